@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	editorTemplate = `
+# Write the message for the listed events. Lines starting with '#' are
+# ignored, an empty message aborts the operation.
+`
+)
+
+// serverEvents are identifiers of events on a single server.
+type serverEvents struct {
+	Server
+
+	identifiers []string
+}
+
+// updateEvents performs actions of the options on events of every server
+// after the confirmation, the message is asked in $EDITOR when edit is set.
+func updateEvents(
+	ctx context.Context,
+	events []serverEvents,
+	options zabbix.AcknowledgeOptions,
+	edit bool,
+	confirmation bool,
+) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if confirmation {
+		if !confirmAcknowledge(describeAcknowledge(options, edit)) {
+			return nil
+		}
+	}
+
+	if edit {
+		var err error
+		options.Message, err = editMessage()
+		if err != nil {
+			return err
+		}
+
+		if options.Message == "" {
+			return errors.New("aborting due to empty message")
+		}
+	}
+
+	for _, server := range events {
+		err := withSpinner(
+			":: Updating specified events",
+			func() error {
+				return server.Client.Acknowledge(
+					ctx, server.identifiers, options,
+				)
+			},
+		)
+		if err != nil {
+			return karma.Format(
+				err,
+				"can't update events %s",
+				server.identifiers,
+			)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, ":: Done")
+
+	return nil
+}
+
+// parseAcknowledgeOptions returns actions for events of the listed triggers
+// given on the command line, ok is false when no action is requested.
+func parseAcknowledgeOptions(
+	args map[string]interface{},
+) (options zabbix.AcknowledgeOptions, ok bool, err error) {
+	var (
+		message, _       = args["--message"].(string)
+		edit, _          = args["--edit"].(bool)
+		severity, _      = args["--change-severity"].(string)
+		suppressUntil, _ = args["--suppress-until"].(string)
+	)
+
+	options.Acknowledge, _ = args["--acknowledge"].(bool)
+	options.Unacknowledge, _ = args["--unack"].(bool)
+	options.Close, _ = args["--close"].(bool)
+	options.Message = message
+
+	if edit && message != "" {
+		return options, false, errors.New(
+			"--message and --edit are mutually exclusive",
+		)
+	}
+
+	if options.Acknowledge && options.Unacknowledge {
+		return options, false, errors.New(
+			"--acknowledge and --unack are mutually exclusive",
+		)
+	}
+
+	if severity != "" {
+		value, err := zabbix.ParseSeverity(severity)
+		if err != nil {
+			return options, false, err
+		}
+
+		options.Severity = &value
+	}
+
+	if suppressUntil != "" {
+		options.Suppress = true
+
+		// zero suppresses events indefinitely
+		if suppressUntil != "indefinitely" {
+			options.SuppressUntil, err = parseDateTime(suppressUntil)
+			if err != nil {
+				return options, false, err
+			}
+		}
+	}
+
+	ok = options.Acknowledge || options.Unacknowledge || options.Close ||
+		options.Message != "" || edit ||
+		options.Severity != nil || options.Suppress
+
+	return options, ok, nil
+}
+
+// describeAcknowledge returns actions of the options for the confirmation.
+func describeAcknowledge(options zabbix.AcknowledgeOptions, edit bool) string {
+	actions := []string{}
+
+	if options.Acknowledge {
+		actions = append(actions, "acknowledge")
+	}
+
+	if options.Unacknowledge {
+		actions = append(actions, "unacknowledge")
+	}
+
+	if options.Message != "" || edit {
+		actions = append(actions, "add message")
+	}
+
+	if options.Severity != nil {
+		actions = append(
+			actions, "change severity to "+options.Severity.String(),
+		)
+	}
+
+	if options.Suppress {
+		if options.SuppressUntil == 0 {
+			actions = append(actions, "suppress indefinitely")
+		} else {
+			actions = append(
+				actions,
+				"suppress until "+time.Unix(options.SuppressUntil, 0).
+					Format(zabbix.TimeFormat),
+			)
+		}
+	}
+
+	if options.Close {
+		actions = append(actions, "close")
+	}
+
+	return strings.Join(actions, ", ")
+}
+
+// editMessage opens $EDITOR for writing the message, lines starting with '#'
+// are stripped.
+func editMessage() (string, error) {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	file, err := os.CreateTemp("", "zabbixctl-message-*.txt")
+	if err != nil {
+		return "", karma.Format(err, "can't create message file")
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(editorTemplate)
+	if err != nil {
+		file.Close()
+		return "", karma.Format(err, "can't write message file")
+	}
+
+	file.Close()
+
+	// EDITOR can contain arguments, e.g. 'code --wait'
+	command := exec.Command("sh", "-c", editor+` "$0"`, file.Name())
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stderr
+	command.Stderr = os.Stderr
+
+	err = command.Run()
+	if err != nil {
+		return "", karma.Format(err, "editor %s failed", editor)
+	}
+
+	file, err = os.Open(file.Name())
+	if err != nil {
+		return "", karma.Format(err, "can't read message file")
+	}
+	defer file.Close()
+
+	lines := []string{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "#") {
+			continue
+		}
+
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", karma.Format(err, "can't read message file")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
+
+func confirmAcknowledge(actions string) bool {
+	var value string
+	fmt.Fprintf(os.Stderr, "\n:: Proceed with %s? [Y/n]: ", actions)
+	_, err := fmt.Scanln(&value)
+	if err != nil {
+		debugf("Error: %+v", err)
+	}
+	return value == "" || value == "Y" || value == "y"
+}
@@ -3,25 +3,57 @@ package main
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jinzhu/configor"
 )
 
+var (
+	reCurrentContext = regexp.MustCompile(`(?m)^[ \t]*current-context[ \t]*=.*$`)
+	reTable          = regexp.MustCompile(`(?m)^[ \t]*\[`)
+)
+
+type ServerConfig struct {
+	Address  string `toml:"address"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	Token    string `toml:"token"`
+	Insecure string `toml:"insecure"`
+}
+
 type Config struct {
-	Server struct {
-		Address  string `toml:"address" required:"true"`
-		Username string `toml:"username" required:"true"`
-		Password string `toml:"password" required:"false"`
-		Insecure string `toml:"insecure" required:"false" default:"false"`
-	} `toml:"server"`
-	Session struct {
+	CurrentContext string                  `toml:"current-context"`
+	Server         ServerConfig            `toml:"server"`
+	Servers        map[string]ServerConfig `toml:"servers"`
+	Session        struct {
 		Path string `toml:"path"`
 	} `toml:"session"`
+	Hooks struct {
+		Command string `toml:"command"`
+		URL     string `toml:"url"`
+		State   string `toml:"state"`
+	} `toml:"hooks"`
+	Search struct {
+		Mode          string `toml:"mode"`
+		CaseSensitive bool   `toml:"case-sensitive"`
+	} `toml:"search"`
+
+	path string
+}
+
+// Context is a Zabbix server selected from the configuration, the unnamed
+// context stands for the [server] section.
+type Context struct {
+	Name        string
+	Server      ServerConfig
+	SessionPath string
 }
 
 func NewConfig(path string) (*Config, error) {
-	config := &Config{}
+	config := &Config{path: path}
 	err := configor.Load(config, path)
 	if err != nil {
 		return nil, err
@@ -31,15 +63,162 @@ func NewConfig(path string) (*Config, error) {
 		config.Session.Path = os.Getenv("HOME") + "/" + strings.TrimPrefix(config.Session.Path, "~/")
 	}
 
+	if strings.HasPrefix(config.Hooks.State, "~/") {
+		config.Hooks.State = os.Getenv("HOME") + "/" + strings.TrimPrefix(config.Hooks.State, "~/")
+	}
+
+	return config, nil
+}
+
+// GetContexts returns sorted names of the contexts defined in [servers.<name>]
+// sections.
+func (config *Config) GetContexts() []string {
+	names := []string{}
+	for name := range config.Servers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// GetContext returns the context with the given name, the current context is
+// used when the name is empty. ZABBIXCTL_TOKEN and ZABBIXCTL_USERPASS
+// variables override credentials of the returned context.
+func (config *Config) GetContext(name string) (*Context, error) {
+	return config.getContext(name, true)
+}
+
+// getContext returns the context with the given name, credentials are
+// overridden by environment variables only when overrides is set, so they
+// aren't sent to servers of other contexts.
+func (config *Config) getContext(name string, overrides bool) (*Context, error) {
+	if name == "" {
+		name = config.CurrentContext
+	}
+
+	context := &Context{
+		Name:        name,
+		SessionPath: config.Session.Path,
+	}
+
+	switch {
+	case name != "":
+		server, ok := config.Servers[name]
+		if !ok {
+			return nil, fmt.Errorf(
+				"context '%s' not found in %s, available contexts: %s",
+				name, config.path, strings.Join(config.GetContexts(), ", "),
+			)
+		}
+
+		context.Server = server
+
+		// every context keeps its own session
+		if context.SessionPath != "" {
+			context.SessionPath += "." + name
+		}
+
+	case config.Server.Address != "":
+		context.Server = config.Server
+
+	case len(config.Servers) == 1:
+		context.Name = config.GetContexts()[0]
+		return config.getContext(context.Name, overrides)
+
+	default:
+		return nil, fmt.Errorf(
+			"no [server] section and no current-context found in %s",
+			config.path,
+		)
+	}
+
+	err := context.Server.validate(config.path, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	return context, nil
+}
+
+// UseContext sets current-context in the configuration file, other contents
+// of the file are kept as is.
+func (config *Config) UseContext(name string) error {
+	if _, ok := config.Servers[name]; !ok {
+		return fmt.Errorf(
+			"context '%s' not found in %s, available contexts: %s",
+			name, config.path, strings.Join(config.GetContexts(), ", "),
+		)
+	}
+
+	contents, err := os.ReadFile(config.path)
+	if err != nil {
+		return err
+	}
+
+	line := "current-context = " + strconv.Quote(name)
+
+	// only the top-level key is replaced, tables may have keys of the same
+	// name
+	top := len(contents)
+	if table := reTable.FindIndex(contents); table != nil {
+		top = table[0]
+	}
+
+	if key := reCurrentContext.FindIndex(contents[:top]); key != nil {
+		contents = append(
+			append(append([]byte{}, contents[:key[0]]...), line...),
+			contents[key[1]:]...,
+		)
+	} else {
+		// top-level keys must precede any table
+		contents = append([]byte(line+"\n\n"), contents...)
+	}
+
+	err = os.WriteFile(config.path, contents, 0600)
+	if err != nil {
+		return err
+	}
+
+	config.CurrentContext = name
+
+	return nil
+}
+
+func (server *ServerConfig) validate(path string, overrides bool) error {
+	if server.Address == "" {
+		return fmt.Errorf("zabbix server address not found in %s", path)
+	}
+
+	if server.Insecure == "" {
+		server.Insecure = "false"
+	}
+
+	// override configfile supplied API token with env variable if one exists
+	envToken, ok := os.LookupEnv("ZABBIXCTL_TOKEN")
+	if ok && overrides {
+		server.Token = envToken
+	}
+	// API token replaces user credentials
+	if server.Token != "" {
+		return nil
+	}
+
+	if server.Username == "" {
+		return fmt.Errorf("zabbix username or API token not found in %s or env variable ZABBIXCTL_TOKEN", path)
+	}
+
 	// override configfile supplied password with env variable if one exists
 	env_userpass, ok := os.LookupEnv("ZABBIXCTL_USERPASS")
+	ok = ok && overrides
 	if ok {
-		config.Server.Password = env_userpass
+		server.Password = env_userpass
 	}
 	// must have either a password in the configfile or env variable
-	if !ok && config.Server.Password == "" {
-		return nil, fmt.Errorf("zabbix user password not found in %s or env variable ZABBIXCTL_USERPASS", path)
+	if !ok && server.Password == "" {
+		return fmt.Errorf("zabbix user password not found in %s or env variable ZABBIXCTL_USERPASS", path)
 	}
 
-	return config, nil
+	return nil
 }
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	configContexts = `# servers
+[servers.prod]
+  address  = "https://zabbix.prod.local"
+  username = "admin"
+  password = "password"
+
+[servers.lab]
+  address = "https://zabbix.lab.local"
+  token   = "token"
+
+[session]
+  path = "/tmp/zabbixctl.session"
+`
+)
+
+func TestConfigContexts(t *testing.T) {
+	test := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "zabbixctl.conf")
+	test.NoError(os.WriteFile(path, []byte(configContexts), 0600))
+
+	config, err := NewConfig(path)
+	test.NoError(err)
+	test.Equal([]string{"lab", "prod"}, config.GetContexts())
+
+	_, err = config.GetContext("")
+	test.Error(err)
+
+	server, err := config.GetContext("prod")
+	test.NoError(err)
+	test.Equal("https://zabbix.prod.local", server.Server.Address)
+	test.Equal("/tmp/zabbixctl.session.prod", server.SessionPath)
+
+	test.NoError(config.UseContext("lab"))
+	test.Error(config.UseContext("staging"))
+
+	config, err = NewConfig(path)
+	test.NoError(err)
+	test.Equal("lab", config.CurrentContext)
+
+	server, err = config.GetContext("")
+	test.NoError(err)
+	test.Equal("lab", server.Name)
+	test.Equal("token", server.Server.Token)
+	test.Equal("/tmp/zabbixctl.session.lab", server.SessionPath)
+
+	test.NoError(config.UseContext("prod"))
+
+	contents, err := os.ReadFile(path)
+	test.NoError(err)
+	test.Equal(
+		"current-context = \"prod\"\n\n"+configContexts,
+		string(contents),
+	)
+}
+
+func TestConfigUseContext_TopLevelKey(t *testing.T) {
+	test := assert.New(t)
+
+	contents := `# contexts
+
+current-context = "prod"
+
+[servers.prod]
+  address  = "https://zabbix.prod.local"
+  username = "admin"
+  password = "password"
+
+[servers.lab]
+  address         = "https://zabbix.lab.local"
+  token           = "token"
+  current-context = "kept"
+`
+
+	path := filepath.Join(t.TempDir(), "zabbixctl.conf")
+	test.NoError(os.WriteFile(path, []byte(contents), 0600))
+
+	config, err := NewConfig(path)
+	test.NoError(err)
+	test.NoError(config.UseContext("lab"))
+
+	updated, err := os.ReadFile(path)
+	test.NoError(err)
+	test.Equal(
+		strings.Replace(
+			contents,
+			`current-context = "prod"`, `current-context = "lab"`, 1,
+		),
+		string(updated),
+	)
+}
+
+func TestConfigContexts_EnvOverrides(t *testing.T) {
+	test := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "zabbixctl.conf")
+	test.NoError(os.WriteFile(
+		path, []byte("current-context = \"prod\"\n\n"+configContexts), 0600,
+	))
+
+	t.Setenv("ZABBIXCTL_TOKEN", "env-token")
+
+	config, err := NewConfig(path)
+	test.NoError(err)
+
+	server, err := config.GetContext("lab")
+	test.NoError(err)
+	test.Equal("env-token", server.Server.Token)
+
+	server, err = config.getContext("lab", false)
+	test.NoError(err)
+	test.Equal("token", server.Server.Token)
+
+	server, err = config.getContext("prod", false)
+	test.NoError(err)
+	test.Empty(server.Server.Token)
+	test.Equal("password", server.Server.Password)
+}
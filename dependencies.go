@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	// dependenciesDepth limits levels of upstream triggers retrieved for
+	// listed triggers
+	dependenciesDepth = 16
+)
+
+// dependenciesFields are fields of triggers returned by selectDependencies.
+var dependenciesFields = []string{"triggerid", "description"}
+
+// triggerDependencies are triggers of a server linked by their dependencies,
+// upstream triggers are triggers the trigger depends on, downstream triggers
+// depend on the trigger.
+type triggerDependencies struct {
+	triggers   map[string]zabbix.Trigger
+	upstream   map[string][]string
+	downstream map[string][]string
+}
+
+// getTriggerDependencies returns the listed triggers along with all their
+// upstream triggers, upstream triggers which are not listed are retrieved.
+func getTriggerDependencies(
+	ctx context.Context,
+	client *zabbix.Zabbix,
+	listed []zabbix.Trigger,
+) (*triggerDependencies, error) {
+	dependencies := &triggerDependencies{
+		triggers:   map[string]zabbix.Trigger{},
+		upstream:   map[string][]string{},
+		downstream: map[string][]string{},
+	}
+
+	for _, trigger := range listed {
+		dependencies.add(trigger)
+	}
+
+	for depth := 0; depth < dependenciesDepth; depth++ {
+		missing := dependencies.missing()
+		if len(missing) == 0 {
+			break
+		}
+
+		triggers, err := client.GetTriggers(ctx, zabbix.Params{
+			"triggerids":         missing,
+			"skipDependent":      false,
+			"selectDependencies": dependenciesFields,
+		})
+		if err != nil {
+			return nil, karma.Format(
+				err,
+				"can't obtain upstream triggers %s", missing,
+			)
+		}
+
+		for _, trigger := range triggers {
+			dependencies.add(trigger)
+		}
+
+		// dependencies on triggers which are not monitored don't mask
+		// problems, so they are dropped
+		for _, identifier := range missing {
+			if _, ok := dependencies.triggers[identifier]; !ok {
+				dependencies.drop(identifier)
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (dependencies *triggerDependencies) add(trigger zabbix.Trigger) {
+	if _, ok := dependencies.triggers[trigger.ID]; ok {
+		return
+	}
+
+	dependencies.triggers[trigger.ID] = trigger
+
+	for _, upstream := range trigger.Dependencies {
+		dependencies.upstream[trigger.ID] = append(
+			dependencies.upstream[trigger.ID], upstream.ID,
+		)
+
+		dependencies.downstream[upstream.ID] = append(
+			dependencies.downstream[upstream.ID], trigger.ID,
+		)
+	}
+}
+
+// missing returns identifiers of upstream triggers which are not retrieved.
+func (dependencies *triggerDependencies) missing() []string {
+	missing := []string{}
+	seen := map[string]bool{}
+
+	for _, upstream := range dependencies.upstream {
+		for _, identifier := range upstream {
+			if _, ok := dependencies.triggers[identifier]; ok || seen[identifier] {
+				continue
+			}
+
+			seen[identifier] = true
+			missing = append(missing, identifier)
+		}
+	}
+
+	return missing
+}
+
+// drop removes links to the upstream trigger which can't be retrieved.
+func (dependencies *triggerDependencies) drop(identifier string) {
+	for _, downstream := range dependencies.downstream[identifier] {
+		upstream := []string{}
+		for _, candidate := range dependencies.upstream[downstream] {
+			if candidate != identifier {
+				upstream = append(upstream, candidate)
+			}
+		}
+
+		dependencies.upstream[downstream] = upstream
+	}
+
+	delete(dependencies.downstream, identifier)
+}
+
+// roots returns identifiers of the topmost upstream triggers of the listed
+// triggers in order of the listing.
+func (dependencies *triggerDependencies) roots(listed []zabbix.Trigger) []string {
+	var (
+		roots   = []string{}
+		visited = map[string]bool{}
+		walk    func(identifier string)
+	)
+
+	walk = func(identifier string) {
+		if visited[identifier] {
+			return
+		}
+
+		visited[identifier] = true
+
+		if len(dependencies.upstream[identifier]) == 0 {
+			roots = append(roots, identifier)
+			return
+		}
+
+		for _, upstream := range dependencies.upstream[identifier] {
+			walk(upstream)
+		}
+	}
+
+	for _, trigger := range listed {
+		walk(trigger.ID)
+	}
+
+	return roots
+}
+
+// write writes trees of the listed triggers starting from their roots, the
+// description of every downstream trigger is indented under its upstream
+// trigger. A trigger which depends on several triggers is written once,
+// under the first of them reached from the roots.
+func (dependencies *triggerDependencies) write(
+	table io.Writer,
+	server *serverTriggers,
+	listed []zabbix.Trigger,
+	extended ExtendedOutput,
+	allContexts bool,
+) {
+	var (
+		roots    = dependencies.roots(listed)
+		claimed  = map[string]bool{}
+		children = map[string][]string{}
+		claim    func(identifier string)
+	)
+
+	// a trigger can't depend on itself, but claiming every trigger once
+	// keeps loops from walking forever as well
+	claim = func(identifier string) {
+		for _, child := range dependencies.downstream[identifier] {
+			if claimed[child] {
+				continue
+			}
+
+			claimed[child] = true
+			children[identifier] = append(children[identifier], child)
+
+			claim(child)
+		}
+	}
+
+	for _, root := range roots {
+		claimed[root] = true
+	}
+
+	for _, root := range roots {
+		claim(root)
+	}
+
+	var walk func(identifier, prefix, indent string)
+
+	walk = func(identifier, prefix, indent string) {
+		trigger := dependencies.triggers[identifier]
+		trigger.Description = prefix + trigger.Description
+
+		writeTriggerRow(
+			table,
+			triggerRow{serverTriggers: server, trigger: trigger},
+			extended,
+			allContexts,
+		)
+
+		downstream := children[identifier]
+		for index, child := range downstream {
+			if index == len(downstream)-1 {
+				walk(child, indent+"└─ ", indent+"   ")
+			} else {
+				walk(child, indent+"├─ ", indent+"│  ")
+			}
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, "", "")
+	}
+}
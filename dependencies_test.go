@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDependentTrigger(id, description string, upstream ...string) zabbix.Trigger {
+	trigger := zabbix.Trigger{ID: id, Description: description}
+	trigger.LastEvent.ID = "1" + id
+
+	for _, identifier := range upstream {
+		trigger.Dependencies = append(
+			trigger.Dependencies,
+			zabbix.TriggerDependency{ID: identifier},
+		)
+	}
+
+	return trigger
+}
+
+func TestTriggerDependencies(t *testing.T) {
+	test := assert.New(t)
+
+	dependencies := &triggerDependencies{
+		triggers:   map[string]zabbix.Trigger{},
+		upstream:   map[string][]string{},
+		downstream: map[string][]string{},
+	}
+
+	// switch <- router <- web, db; disk is unrelated, cpu depends on a
+	// trigger which can't be retrieved
+	listed := []zabbix.Trigger{
+		newDependentTrigger("3", "web down", "2"),
+		newDependentTrigger("5", "disk full"),
+		newDependentTrigger("4", "db down", "2"),
+		newDependentTrigger("6", "cpu load", "9"),
+	}
+
+	for _, trigger := range listed {
+		dependencies.add(trigger)
+	}
+
+	test.ElementsMatch([]string{"2", "9"}, dependencies.missing())
+
+	dependencies.add(newDependentTrigger("2", "router down", "1"))
+	dependencies.add(newDependentTrigger("1", "switch down"))
+	dependencies.drop("9")
+
+	test.Empty(dependencies.missing())
+	test.Equal([]string{"1", "5", "6"}, dependencies.roots(listed))
+
+	var (
+		buffer bytes.Buffer
+		table  = tabwriter.NewWriter(&buffer, 1, 4, 1, '|', 0)
+		server = &serverTriggers{Server: Server{Name: "prod"}}
+	)
+
+	dependencies.write(table, server, listed, ExtendedOutputNone, false)
+	table.Flush()
+
+	descriptions := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		descriptions = append(
+			descriptions, line[strings.LastIndex(line, "|")+1:],
+		)
+	}
+
+	test.Equal([]string{
+		"switch down",
+		"└─ router down",
+		"   ├─ web down",
+		"   └─ db down",
+		"disk full",
+		"cpu load",
+	}, descriptions)
+}
+
+func TestTriggerDependencies_SeveralUpstream(t *testing.T) {
+	test := assert.New(t)
+
+	dependencies := &triggerDependencies{
+		triggers:   map[string]zabbix.Trigger{},
+		upstream:   map[string][]string{},
+		downstream: map[string][]string{},
+	}
+
+	// app depends on both web and db, web depends on db as well
+	listed := []zabbix.Trigger{
+		newDependentTrigger("3", "app down", "1", "2"),
+		newDependentTrigger("2", "web down", "1"),
+		newDependentTrigger("1", "db down"),
+	}
+
+	for _, trigger := range listed {
+		dependencies.add(trigger)
+	}
+
+	test.Empty(dependencies.missing())
+
+	var (
+		buffer bytes.Buffer
+		table  = tabwriter.NewWriter(&buffer, 1, 4, 1, '|', 0)
+		server = &serverTriggers{Server: Server{Name: "prod"}}
+	)
+
+	dependencies.write(table, server, listed, ExtendedOutputNone, false)
+	table.Flush()
+
+	descriptions := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		descriptions = append(
+			descriptions, line[strings.LastIndex(line, "|")+1:],
+		)
+	}
+
+	test.Equal([]string{
+		"db down",
+		"├─ app down",
+		"└─ web down",
+	}, descriptions)
+}
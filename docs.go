@@ -28,13 +28,75 @@ Subsequent runs will use saved session instead of new re-authorizing.
 Zabbix sessions have a default TTL of 15 minutes, so if the saved Zabbix
 session is outdated, zabbixctl will repeat authorization and rewrite the
 session file.
+The same happens when the server rejects the saved session, for example
+after the session timeout was changed or the session was terminated.
+
+  On Zabbix 5.4 and above an API token can be used instead of user
+credentials, set it using the 'token' setting in the [server] section or
+the ZABBIXCTL_TOKEN environment variable, the session file isn't used then.
+
+  Several Zabbix servers can be configured as named contexts using
+[servers.<name>] sections, the context is selected using the --context flag
+or the current-context setting, which is set by 'zabbixctl config
+use-context <name>'. Every context keeps its own session file, the context
+name is appended to the session path. ZABBIXCTL_TOKEN and
+ZABBIXCTL_USERPASS variables apply to the selected context only, with -A to
+the current context:
+
+    current-context = "prod"
+
+    [servers.prod]
+      address  = "https://zabbix.prod.local"
+      username = "admin"
+      password = "password"
+
+    [servers.lab]
+      address = "https://zabbix.lab.local"
+      token   = "0424bd59b807674191e7d77572075f33"
+
+  The --watch mode runs hooks for every new or resolved problem: the
+command gets the problem as JSON on stdin and the URL gets it as the body of
+the POST request, the 'event' field is either 'problem' or 'resolved'.
+Problems are resolved by the OK state of their triggers, which are checked
+on the server when they aren't listed.
+Notified problems are kept in the state file, so they aren't notified again
+after restart, the file is ~/.cache/zabbixctl.hooks by default. Problems
+which exist when the state file is created by the first run are recorded
+without running hooks:
+
+    [hooks]
+      command = "notify-chat"
+      url     = "https://bots.local/zabbix"
+      state   = "~/.cache/zabbixctl.hooks"
+
+  The /<pattern> argument matches using the mode set by --match, the
+default mode and case sensitivity can be set in the [search] section:
+
+    [search]
+      mode           = "regex"
+      case-sensitive = true
 
 Usage:
-  zabbixctl [options] -T [/<pattern>...]
-  zabbixctl [options] -L <hostname>... [/<pattern>...]
-  zabbixctl [options] -G [/<pattern>...]
-  zabbixctl [options] -M [<hostname>...] [/<pattern>...]
+  zabbixctl [options] -T [-A] [--tag <tag>]... [--group <group>]...
+            [--template <template>]... [--exclude <exclude>]...
+            [/<pattern>...] [!/<pattern>...]
+  zabbixctl [options] -P [-A] [--tag <tag>]... [--group <group>]...
+            [--template <template>]... [--exclude <exclude>]...
+            [/<pattern>...] [!/<pattern>...]
+  zabbixctl [options] -L [-A] [--exclude <exclude>]... <hostname>...
+            [/<pattern>...] [!/<pattern>...]
+  zabbixctl [options] -G [--exclude <exclude>]... [/<pattern>...]
+            [!/<pattern>...]
+  zabbixctl [options] -M [--exclude <exclude>]... [<hostname>...]
+            [/<pattern>...] [!/<pattern>...]
   zabbixctl [options] -H [<pattern>] <hostname>
+  zabbixctl [options] events [-A] <target>
+  zabbixctl [options] report availability [-A] [--tag <tag>]...
+            [--group <group>]... [--template <template>]...
+            [--exclude <exclude>]... [/<pattern>...] [!/<pattern>...]
+  zabbixctl [options] config use-context <context>
+  zabbixctl [options] config get-contexts
+  zabbixctl [options] api <method> [<params>] [--jq <path>]
   zabbixctl -h | --help
   zabbixctl --version
 
@@ -48,9 +110,20 @@ Workflow options:
     -y --only-nack
       Show only not acknowledged triggers.
 
-    -x --severity
-      Specify minimum trigger severity.  Once for information, twice for
-      warning, three for disaster, four for high, five for disaster.
+    -x
+      Specify minimum trigger severity: once for information, twice for
+      warning, thrice for average, four times for high, five times for
+      disaster.
+
+    --severity <severity>
+      Show triggers of severities given by the name or the number: the
+      severity and above, '=severity' for the severity only or 'from..till'
+      for the range, for example:
+        zabbixctl -Tp --severity high
+        zabbixctl -Tp --severity warn..high
+        zabbixctl -Tp --severity =disaster
+      Names are none, info, warn, avg, high, disaster, their full names and
+      names of severities configured on the server (Zabbix 5.2+).
 
     -p --problem
       Show triggers that have a problem state.
@@ -59,8 +132,8 @@ Workflow options:
       Show triggers that have recently been in a problem state.
 
     -s --since <date>
-      Show triggers that have changed their state after the given time.
-      [default: 7 days ago]
+      Show triggers that have changed their state after the given time,
+      7 days ago by default.
 
     -u --until <date>
       Show triggers that have changed their state before the given time.
@@ -68,6 +141,42 @@ Workflow options:
     -m --maintenance
       Show hosts in maintenance.
 
+    --with-dependent
+      Show triggers which depend on triggers in a problem state, such
+      triggers are skipped by default.
+
+    --deps
+      Show triggers as trees of their dependencies: every trigger is listed
+      under the trigger it depends on, so the root cause of a cascade is on
+      top. Triggers the listed triggers depend on are shown as well, a
+      trigger which depends on several triggers is shown only once, under
+      the first of them in the tree. Triggers which depend on the listed
+      triggers are shown only if they are listed too.
+
+    --tag <tag>
+      Show triggers with the matching tag, can be repeated:
+        key=value   value equals;
+        key~value   value contains;
+        key!=value  value doesn't equal;
+        key!~value  value doesn't contain;
+        key         tag exists;
+        !key        tag doesn't exist.
+      Operators other than '=' and '~' require Zabbix 5.4+.
+
+    --tag-eval <eval>
+      Combine tag filters: 'and' requires filters of every tag to match,
+      filters of the same tag are combined by OR; 'or' requires any filter to
+      match.
+      [default: and]
+
+    --group <group>
+      Show triggers of hosts in the host group, the name can contain the
+      wildcard character '*', can be repeated.
+
+    --template <template>
+      Show triggers of hosts linked to the template, the name can contain the
+      wildcard character '*', can be repeated.
+
     -i --sort <fields>
       Show triggers sorted by specified fields.
       [default: lastchange,priority]
@@ -83,14 +192,90 @@ Workflow options:
     -k --acknowledge
       Acknowledge all retrieved triggers.
 
+    --message <text>
+      Add the message to events of all retrieved triggers.
+
+    --edit
+      Write the message for events of all retrieved triggers in the editor
+      set by the EDITOR variable.
+
+    --close
+      Close problems of all retrieved triggers, triggers must allow manual
+      close.
+
+    --unack
+      Unacknowledge all retrieved triggers, requires Zabbix 5.0+.
+
+    --change-severity <severity>
+      Change severity of events of all retrieved triggers, severity is a
+      number or one of: none, info, warn, avg, high, disaster.
+
+    --suppress-until <date>
+      Suppress problems of all retrieved triggers until the given time or
+      'indefinitely', requires Zabbix 6.4+.
+
+    --disable
+      Disable all retrieved triggers and print the changed triggers, for
+      example, disable triggers of the flapping check during the incident:
+        zabbixctl -Tp /replication --disable
+
+    --enable
+      Enable all retrieved triggers, disabled triggers are retrieved instead
+      of monitored ones.
+
     -f --noconfirm
-      Do not prompt for acknowledge confirmation.
+      Do not prompt for confirmation of actions on triggers.
+
+    --interactive
+      Show triggers in the full-screen interactive mode: move through the
+      list, select several triggers, acknowledge them with a message, open
+      the latest value and history of the item and filter the list while
+      typing. Press 'q' to quit.
+
+    --watch <interval>
+      Refresh the listing every interval, for example 30s, and redraw it in
+      place. The header shows amounts of problems by severity, new problems
+      are highlighted and resolved ones are listed until the next refresh.
+      Type an event ID and optionally a message to acknowledge the event.
+      Hooks of the [hooks] section are run for new and resolved problems.
+
+    --summary
+      Show amounts of triggers in a problem state by severity, host group,
+      host and acknowledgement instead of the listing, for example, for the
+      shift handover:
+        zabbixctl -T --summary --top 5
+
+    --top <amount>
+      Show specified amount of host groups and hosts with most problems in
+      the summary, zero shows all of them.
+      [default: 10]
 
     -d --extended
       Once for printing item's last value from the first component of the
       trigger expression. Twice for adding last value change date. Thrice for
       printing item description as well.
 
+    --functions
+      Show every function of the trigger expression under the trigger: the
+      function with its parameters, the item, the last value of the item and
+      its time.
+
+    --expression
+      Show the trigger expression with last values of items substituted for
+      functions under the trigger, '?' stands for an item without data.
+
+  -P --problems
+    Search Zabbix problems, a trigger can have several problems at once.
+    Problems are shown with the start time, duration and the last message
+    of the update with its author. Filtering options of triggers and
+    actions on their events are supported as well, for example, close
+    problems of the last hour matching the word 'cache':
+      zabbixctl -P -s '1 hour ago' /cache --close
+    Ongoing problems of any age are shown unless --since is set.
+
+    -d --extended
+      Show tags and all messages of every problem.
+
   -L --latest-data
     Search and show latest data for specified host(s). Hosts can be searched for
     using a wildcard character '*'.  Data can be filtered using the /<pattern>
@@ -156,12 +341,122 @@ Workflow options:
     -r --remove <hostname>
       Remove specified <hostname>.
 
+  events <target>
+    Show the timeline of PROBLEM and OK events of the trigger, when the
+    target is the trigger ID, or of triggers of hosts matching the target,
+    which can contain the wildcard character '*'. Every problem is shown
+    with its duration, updates made by users and alerts sent by actions.
+    The period is set by --since and --until, for example:
+      zabbixctl events 'dbnode-*' -s 'yesterday 18:00' -u 'today 09:00'
+
+  report availability
+    Report how long every trigger was in a problem state within the window
+    set by --since and --until: the amount of problems, the problem time,
+    the mean time to resolve problems (MTTR) and the availability. The
+    period named by --since is reported as a whole when --until isn't set,
+    for example, the previous month for the host group:
+      zabbixctl report availability -s 'last month' --group 'Databases/*'
+    Triggers can be filtered using --tag, --group, --template and the
+    /<pattern> argument like for -T. Problems are counted from the start of
+    the window when they began earlier.
+
+    --by <field>
+      Report every trigger or every host, one of: trigger, host. The host
+      is in a problem state while any of its triggers is.
+      [default: trigger]
+
+  api <method> [<params>]
+    Call arbitrary Zabbix API method and print its result as JSON, the
+    session and API version handling is the same as for other commands.
+    Params are given as JSON, '@<file>' reads them from the file and '-'
+    reads them from stdin, default is '{}'. The program exits with status 2
+    when the API returns an error, for example:
+      zabbixctl api host.get '{"output": ["host"], "limit": 5}'
+
+    --jq <path>
+      Print only values found by the dotted path, for example '.0.host' or
+      '.[].host', where '[]' stands for every element of an array. Strings
+      are printed without quotes.
+
+  config use-context <context>
+    Set current-context in the configuration file.
+
+  config get-contexts
+    List contexts configured in the configuration file, the current context
+    is marked with '*'.
+
 
 Misc options:
   -c --config <path>
     Use specified configuration file.
     [default: $HOME/.config/zabbixctl.conf]
 
+  --context <name>
+    Use specified context from the configuration file instead of the
+    current-context.
+
+  -A --all-contexts
+    Query servers of all contexts in parallel and merge the output, which
+    gets the context name as the first column. Servers which can't be
+    reached are reported and skipped. Only for -T, -P, -L, events and
+    reports, triggers, problems and events are sorted by the time of the
+    change.
+
+  --output <format>
+    Print listings of -T, -P, -L, -G, -M, -H, events and reports in the
+    machine-readable format instead of the table, one of: table, json, ndjson,
+    yaml, csv. Objects have the same fields as in the Zabbix API, filtering by
+    the pattern still applies and spinners are suppressed. The csv output has
+    the header and all columns of the listing unless --columns is set.
+    [default: table]
+
+  --format <template>
+    Print every object of the listing using the Go template, for example:
+    '{{.Host}} {{.Severity}} {{.Age}}'. Fields are the same as in the
+    machine-readable output, along with methods of the objects.
+
+  --match <mode>
+    Match the /<pattern> argument and the filter of --interactive using the
+    mode, one of:
+      fuzzy  letters of the pattern in order with anything between them, so
+             '/cache' matches 'Cache usage' and 'Check process cache';
+      regex  the regular expression, e.g. '/^10\d+ db.*replication';
+      glob   '*' stands for any characters and '?' for a single one;
+      exact  the pattern as is, without special characters.
+    Patterns of every mode match any part of the row, which has several
+    fields, e.g. the host and the description of a trigger, so '/web*' is
+    the same as '/web' and '/db?-*' matches 'db1-a' and 'replica db2-b'.
+    The mode of the [search] section is used by default, otherwise fuzzy.
+
+  --case-sensitive
+    Match the /<pattern> argument case-sensitively, the case is ignored by
+    default.
+
+  --exclude <exclude>
+    Hide objects matching the pattern, the same as the !/<pattern>
+    argument. Both can be repeated and combined with /<pattern>, excluded
+    patterns use the mode of --match, for example, list triggers matching
+    'disk' except backups and temporary volumes:
+      zabbixctl -T /disk '!/backup' --exclude tmp
+    The '!' has to be quoted in interactive shells.
+
+  --columns <names>
+    Print only specified comma-separated columns of the listing:
+      triggers      server, id, triggerid, time, age, severity, status,
+                    ack, host, description, tags, groups, value,
+                    valuetime, item;
+      problems      server, id, triggerid, time, duration, severity,
+                    status, ack, host, name, tags, ackuser, ackmessage;
+      events        server, id, triggerid, time, status, severity,
+                    duration, ack, host, name, acks, alerts;
+      availability  server, triggerid, host, name, severity, problems,
+                    problemtime, problemseconds, mttr, mttrseconds,
+                    availability;
+      latest data   server, host, id, type, name, time, value;
+      groups        id, status, name, users;
+      maintenances  id, name, since, till, status, data, groups, hosts;
+      hosts         id, name.
+
   -v --verbosity
     Specify program output verbosity.
     Once for debug, twice for trace.
@@ -173,16 +468,22 @@ Misc options:
     Show version.
 `)
 	usage = `
-  zabbixctl [options] -T [-v]... [-x]... [-d]... [<pattern>]...
-  zabbixctl [options] -L [-v]... <pattern>...
-  zabbixctl [options] -G [-v]... [<pattern>]...
+  zabbixctl [options] -T [-A] [-v]... [-x]... [-d]... [--tag <tag>]... [--group <group>]... [--template <template>]... [--exclude <exclude>]... [<pattern>]...
+  zabbixctl [options] -P [-A] [-v]... [-x]... [-d]... [--tag <tag>]... [--group <group>]... [--template <template>]... [--exclude <exclude>]... [<pattern>]...
+  zabbixctl [options] -L [-A] [-v]... [--exclude <exclude>]... <pattern>...
+  zabbixctl [options] -G [-v]... [--exclude <exclude>]... [<pattern>]...
   zabbixctl [options] -G [-v]... <pattern>... -a <user>
   zabbixctl [options] -G [-v]... <pattern>... -r <user>
-  zabbixctl [options] -M [-v]... [<pattern>]...
+  zabbixctl [options] -M [-v]... [--exclude <exclude>]... [<pattern>]...
   zabbixctl [options] -M [-v]... [<pattern>]... -a <maintenance>
   zabbixctl [options] -M [-v]... -r <maintenance>
   zabbixctl [options] -H [-v]... [<pattern>]...
   zabbixctl [options] -H [-v]... -r <hostname>
+  zabbixctl [options] events [-A] [-v]... <target>
+  zabbixctl [options] report availability [-A] [-v]... [--tag <tag>]... [--group <group>]... [--template <template>]... [--exclude <exclude>]... [<pattern>]...
+  zabbixctl [options] config use-context <context>
+  zabbixctl [options] config get-contexts
+  zabbixctl [options] api [-v]... <method> [<params>] [--jq <path>]
   zabbixctl -h | --help
   zabbixctl --version
 `
@@ -190,18 +491,40 @@ Misc options:
 Options:
   -T --triggers
     -y --only-nack
-    -x --severity
+    -x
+    --severity <severity>
     -p --problem
     -t --recent
-    -s --since <date>    [default: 7 days ago]
+    -s --since <date>
     -u --until <date>
     -m --maintenance
+    --with-dependent
+    --deps
+    --tag <tag>
+    --tag-eval <eval>    [default: and]
+    --group <group>
+    --template <template>
     -i --sort <fields>   [default: lastchange,priority]
     -o --order <order>   [default: DESC]
     -n --limit <amount>  [default: 0]
     -f --noconfirm
     -k --acknowledge
+    --message <text>
+    --edit
+    --close
+    --unack
+    --change-severity <severity>
+    --suppress-until <date>
+    --disable
+    --enable
+    --summary
+    --top <amount>       [default: 10]
     -d --extended
+    --functions
+    --expression
+    --watch <interval>
+    --interactive
+  -P --problems
   -L --latest-data
     -g --graph
     -w --stacked
@@ -215,7 +538,17 @@ Options:
     --start <date>
     --end <date>
   -H --hosts
+  --by <field>           [default: trigger]
+  --jq <path>
   -c --config <path>     [default: $HOME/.config/zabbixctl.conf]
+  --context <name>
+  -A --all-contexts
+  --output <format>      [default: table]
+  --format <template>
+  --columns <names>
+  --match <mode>
+  --case-sensitive
+  --exclude <exclude>
   -v --verbosity
   -h --help
   --version
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+var (
+	// reFunctionReference matches references of functions in the trigger
+	// expression which isn't expanded, e.g. {13}>5
+	reFunctionReference = regexp.MustCompile(`\{(\d+)\}`)
+)
+
+// detailsIndent returns the indent of lines printed under the description
+// column of the trigger row.
+func detailsIndent(allContexts bool) string {
+	indent := strings.Repeat("\t", 6)
+	if allContexts {
+		indent += "\t"
+	}
+
+	return indent
+}
+
+// writeTriggerFunctions writes every function of the trigger expression with
+// its item and the last value of the item under the trigger row.
+func writeTriggerFunctions(table io.Writer, row triggerRow, allContexts bool) {
+	indent := detailsIndent(allContexts)
+
+	for _, function := range row.trigger.Functions {
+		history, ok := row.history[function.ItemID]
+		if !ok {
+			fmt.Fprintf(
+				table,
+				"%s%s  item %s: no data\n",
+				indent, function.Call(), function.ItemID,
+			)
+
+			continue
+		}
+
+		fmt.Fprintf(
+			table,
+			"%s%s  %s [%s]: %s at %s\n",
+			indent, function.Call(),
+			history.Item.Format(), history.Item.Key,
+			history.History.String(), history.History.DateTime(),
+		)
+	}
+}
+
+// writeTriggerExpression writes the trigger expression with last values of
+// items substituted for functions under the trigger row.
+func writeTriggerExpression(table io.Writer, row triggerRow, allContexts bool) {
+	expression, ok := row.expressions[row.trigger.ID]
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(
+		table,
+		"%s%s\n",
+		detailsIndent(allContexts),
+		substituteExpression(expression, row.trigger, row.history),
+	)
+}
+
+// substituteExpression replaces references of functions in the expression
+// with last values of their items, '?' stands for an item without data.
+func substituteExpression(
+	expression string,
+	trigger zabbix.Trigger,
+	history map[string]zabbix.ItemHistory,
+) string {
+	items := map[string]string{}
+	for _, function := range trigger.Functions {
+		items[function.ID] = function.ItemID
+	}
+
+	return reFunctionReference.ReplaceAllStringFunc(
+		expression,
+		func(reference string) string {
+			identifier := strings.Trim(reference, "{}")
+
+			item, ok := items[identifier]
+			if !ok {
+				return reference
+			}
+
+			value, ok := history[item]
+			if !ok {
+				return "?"
+			}
+
+			return value.History.String()
+		},
+	)
+}
+
+// getServersTriggerExpressions retrieves expressions of triggers which
+// aren't expanded, so functions can be found by their identifiers.
+func getServersTriggerExpressions(
+	ctx context.Context,
+	results []*serverTriggers,
+) error {
+	return withSpinner(
+		":: Requesting expressions of triggers",
+		func() error {
+			for _, result := range results {
+				identifiers := []string{}
+				for _, trigger := range result.triggers {
+					identifiers = append(identifiers, trigger.ID)
+				}
+
+				result.expressions = map[string]string{}
+
+				// trigger.get returns every trigger when triggerids are empty
+				if len(identifiers) == 0 {
+					continue
+				}
+
+				// the triggers are already filtered, disabled ones are
+				// listed with --enable and dependent with --with-dependent
+				triggers, err := result.Client.GetTriggers(ctx, zabbix.Params{
+					"triggerids":        identifiers,
+					"output":            []string{"triggerid", "expression"},
+					"monitored":         nil,
+					"skipDependent":     nil,
+					"selectHosts":       nil,
+					"selectGroups":      nil,
+					"selectLastEvent":   nil,
+					"selectFunctions":   nil,
+					"selectTags":        nil,
+					"expandExpression":  nil,
+					"expandData":        nil,
+					"expandDescription": nil,
+				})
+				if err != nil {
+					return karma.Format(
+						err,
+						"can't obtain expressions of triggers",
+					)
+				}
+
+				for _, trigger := range triggers {
+					result.expressions[trigger.ID] = trigger.Expression
+				}
+			}
+
+			return nil
+		},
+	)
+}
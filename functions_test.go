@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubstituteExpression(t *testing.T) {
+	test := assert.New(t)
+
+	trigger := zabbix.Trigger{
+		Functions: []zabbix.Function{
+			{ID: "13", ItemID: "10"},
+			{ID: "14", ItemID: "20"},
+			{ID: "15", ItemID: "30"},
+		},
+	}
+
+	history := map[string]zabbix.ItemHistory{
+		"10": {History: zabbix.History{Value: "95.2"}},
+		"20": {History: zabbix.History{Value: "7"}},
+	}
+
+	test.Equal(
+		"95.2>90 and 7>5 or ?=0 and {16}>{$LIMIT}",
+		substituteExpression(
+			"{13}>90 and {14}>5 or {15}=0 and {16}>{$LIMIT}",
+			trigger, history,
+		),
+	)
+}
+
+func TestFunctionCall(t *testing.T) {
+	test := assert.New(t)
+
+	testcases := []struct {
+		function zabbix.Function
+		call     string
+	}{
+		{zabbix.Function{Name: "last", Parameter: "$"}, "last()"},
+		{zabbix.Function{Name: "avg", Parameter: "$,5m"}, "avg(5m)"},
+		{zabbix.Function{Name: "avg", Parameter: "5m"}, "avg(5m)"},
+		{zabbix.Function{Name: "nodata", Parameter: ""}, "nodata()"},
+	}
+
+	for _, testcase := range testcases {
+		test.Equal(testcase.call, testcase.function.Call())
+	}
+}
+
+func TestGetServersTriggerExpressions(t *testing.T) {
+	test := assert.New(t)
+
+	testserver := newTriggersTestServer(t)
+	defer testserver.Close()
+
+	client, err := zabbix.NewZabbix(context.Background(), zabbix.Options{
+		Address: testserver.URL,
+		Token:   "token",
+	})
+	if !test.NoError(err) {
+		return
+	}
+
+	results := []*serverTriggers{
+		{
+			Server:   Server{Name: "prod", Client: client},
+			triggers: []zabbix.Trigger{{ID: "1"}, {ID: "2"}},
+		},
+		{
+			Server: Server{Name: "lab", Client: client},
+		},
+	}
+
+	quietMode = true
+	defer func() { quietMode = false }()
+
+	err = getServersTriggerExpressions(context.Background(), results)
+	test.NoError(err)
+
+	test.Equal(
+		map[string]string{"1": "{13}>90", "2": "{14}=0"},
+		results[0].expressions,
+	)
+	test.Empty(results[1].expressions)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+func handleAPI(
+	ctx context.Context,
+	client *zabbix.Zabbix,
+	config *Config,
+	args map[string]interface{},
+) error {
+	var (
+		method    = args["<method>"].(string)
+		source, _ = args["<params>"].(string)
+		path, _   = args["--jq"].(string)
+	)
+
+	params, err := readAPIParams(source)
+	if err != nil {
+		return karma.Format(
+			err,
+			"can't read params for api method %s", method,
+		)
+	}
+
+	var result json.RawMessage
+
+	err = withSpinner(
+		":: Calling api method "+method,
+		func() error {
+			result, err = client.Call(ctx, method, params)
+			return err
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		var output bytes.Buffer
+		err = json.Indent(&output, result, "", "  ")
+		if err != nil {
+			return karma.Format(err, "can't indent api result")
+		}
+
+		fmt.Println(output.String())
+
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return karma.Format(err, "can't decode api result")
+	}
+
+	values, err := extractPath([]interface{}{value}, path)
+	if err != nil {
+		return karma.Format(err, "can't extract '%s' from api result", path)
+	}
+
+	for _, value := range values {
+		// strings are printed unquoted to be usable in scripts
+		if text, ok := value.(string); ok {
+			fmt.Println(text)
+			continue
+		}
+
+		output, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return karma.Format(err, "can't encode extracted value")
+		}
+
+		fmt.Println(string(output))
+	}
+
+	return nil
+}
+
+// readAPIParams reads JSON params from the command line argument, from the
+// file if it starts with '@' or from stdin if it's '-'.
+func readAPIParams(source string) (json.RawMessage, error) {
+	var (
+		params []byte
+		err    error
+	)
+
+	switch {
+	case source == "":
+		params = []byte("{}")
+	case source == "-":
+		params, err = io.ReadAll(os.Stdin)
+	case strings.HasPrefix(source, "@"):
+		params, err = os.ReadFile(strings.TrimPrefix(source, "@"))
+	default:
+		params = []byte(source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !json.Valid(params) {
+		return nil, fmt.Errorf("params are not valid JSON: %s", params)
+	}
+
+	return json.RawMessage(params), nil
+}
+
+// extractPath walks through the values using dotted path like
+// '.0.hosts.[].name', where '[]' stands for every element of an array.
+func extractPath(values []interface{}, path string) ([]interface{}, error) {
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return values, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		found := []interface{}{}
+
+		for _, value := range values {
+			switch typed := value.(type) {
+			case map[string]interface{}:
+				field, ok := typed[key]
+				if !ok {
+					return nil, fmt.Errorf("no field '%s'", key)
+				}
+
+				found = append(found, field)
+
+			case []interface{}:
+				if key == "[]" {
+					found = append(found, typed...)
+					continue
+				}
+
+				index, err := strconv.Atoi(key)
+				if err != nil || index < 0 || index >= len(typed) {
+					return nil, fmt.Errorf(
+						"no index '%s' in array of %d elements",
+						key, len(typed),
+					)
+				}
+
+				found = append(found, typed[index])
+
+			default:
+				return nil, fmt.Errorf("can't get '%s' of scalar value", key)
+			}
+		}
+
+		values = found
+	}
+
+	return values, nil
+}
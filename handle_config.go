@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/reconquest/karma-go"
+)
+
+func handleConfig(
+	config *Config,
+	args map[string]interface{},
+) error {
+	var (
+		contextName, _ = args["<context>"].(string)
+
+		table = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	)
+
+	switch {
+	case args["use-context"].(bool):
+		err := config.UseContext(contextName)
+		if err != nil {
+			return karma.Format(
+				err,
+				"can't switch to context '%s'", contextName,
+			)
+		}
+
+		fmt.Fprintf(os.Stderr, ":: Switched to context %s\n", contextName)
+
+	case args["get-contexts"].(bool):
+		for _, name := range config.GetContexts() {
+			var (
+				server  = config.Servers[name]
+				current = " "
+				user    = server.Username
+			)
+
+			if name == config.CurrentContext {
+				current = "*"
+			}
+
+			if server.Token != "" {
+				user = "<token>"
+			}
+
+			fmt.Fprintf(
+				table,
+				"%s\t%s\t%s\t%s\n",
+				current, name, server.Address, user,
+			)
+		}
+
+		err := table.Flush()
+		if err != nil {
+			debugf("Error: %+v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+// reTriggerID matches the events target which is the trigger identifier
+// rather than the host pattern.
+var reTriggerID = regexp.MustCompile(`^\d+$`)
+
+// serverEventsHistory are events retrieved from a single server.
+type serverEventsHistory struct {
+	Server
+
+	events []zabbix.Event
+}
+
+// eventOutput is an event in the machine-readable output.
+type eventOutput struct {
+	Server string `json:"server,omitempty"`
+	zabbix.Event
+}
+
+// Host returns the name of the event host.
+func (output *eventOutput) Host() string {
+	return output.GetHostName()
+}
+
+// eventRow is an event listed in the output along with its server.
+type eventRow struct {
+	*serverEventsHistory
+
+	event zabbix.Event
+}
+
+var eventColumns = []column[*eventOutput]{
+	{"server", func(output *eventOutput) string { return output.Server }},
+	{"id", func(output *eventOutput) string { return output.ID }},
+	{"triggerid", func(output *eventOutput) string { return output.ObjectID }},
+	{"time", func(output *eventOutput) string { return output.DateTime() }},
+	{"status", func(output *eventOutput) string {
+		return output.StatusProblem()
+	}},
+	{"severity", func(output *eventOutput) string {
+		return output.Severity().String()
+	}},
+	{"duration", func(output *eventOutput) string {
+		return output.Duration()
+	}},
+	{"ack", func(output *eventOutput) string {
+		return output.StatusAcknowledge()
+	}},
+	{"host", func(output *eventOutput) string { return output.Host() }},
+	{"name", func(output *eventOutput) string { return output.Name }},
+	{"acks", func(output *eventOutput) string {
+		return strconv.Itoa(len(output.Acknowledges))
+	}},
+	{"alerts", func(output *eventOutput) string {
+		return strconv.Itoa(len(output.Alerts))
+	}},
+}
+
+func handleEvents(
+	ctx context.Context,
+	servers []Server,
+	config *Config,
+	args map[string]interface{},
+) error {
+	var (
+		target      = args["<target>"].(string)
+		allContexts = args["--all-contexts"].(bool)
+		outputs     = []*eventOutput{}
+
+		table = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	)
+
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
+	params, err := parseEventsParams(args)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*serverEventsHistory, len(servers))
+	for index, server := range servers {
+		results[index] = &serverEventsHistory{Server: server}
+	}
+
+	var errs []error
+
+	err = withSpinner(
+		":: Requesting information about events",
+		func() error {
+			errs = fanOut(len(results), func(index int) error {
+				params, ok, err := resolveEventsTarget(
+					ctx, results[index].Client, target, params,
+				)
+				if err != nil || !ok {
+					return err
+				}
+
+				results[index].events, err = results[index].Client.GetEvents(
+					ctx, params,
+				)
+
+				return err
+			})
+
+			return nil
+		},
+	)
+	if err == nil {
+		results, err = skipFailed(servers, results, errs, "can't obtain zabbix events")
+	}
+	if err != nil {
+		return karma.Format(
+			err,
+			"can't obtain zabbix events",
+		)
+	}
+
+	rows := []eventRow{}
+	for _, result := range results {
+		for _, event := range result.events {
+			rows = append(rows, eventRow{
+				serverEventsHistory: result,
+				event:               event,
+			})
+		}
+	}
+
+	if len(results) > 1 {
+		sortEventsByClock(rows)
+	}
+
+	for _, row := range rows {
+		event := row.event
+
+		if output.custom() {
+			entry := &eventOutput{Event: event}
+			if allContexts {
+				entry.Server = row.Name
+			}
+
+			outputs = append(outputs, entry)
+
+			continue
+		}
+
+		var prefix string
+		if allContexts {
+			prefix = row.Name + "\t"
+		}
+
+		fmt.Fprintf(
+			table,
+			"%s%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			prefix,
+			event.ID, event.DateTime(),
+			event.StatusProblem(),
+			event.Severity(),
+			event.Duration(),
+			event.StatusAcknowledge(),
+			event.GetHostName(),
+			event.Name,
+		)
+
+		// acknowledges and alerts are printed under the name column
+		indent := prefix + strings.Repeat("\t", 7)
+
+		for _, update := range event.Acknowledges {
+			fmt.Fprintf(
+				table,
+				"%s%s %s: %s",
+				indent, update.DateTime(), update.User(), update.Actions(),
+			)
+
+			if update.Message != "" {
+				fmt.Fprintf(table, ": %s", update.Message)
+			}
+
+			fmt.Fprint(table, "\n")
+		}
+
+		for _, alert := range event.Alerts {
+			fmt.Fprintf(
+				table,
+				"%s%s alert to %s: %s (%s",
+				indent, alert.DateTime(), alert.SendTo, alert.Subject,
+				alert.StatusString(),
+			)
+
+			if alert.Error != "" {
+				fmt.Fprintf(table, ": %s", alert.Error)
+			}
+
+			fmt.Fprint(table, ")\n")
+		}
+	}
+
+	if output.custom() {
+		return printRows(output, eventColumns, outputs)
+	}
+
+	return table.Flush()
+}
+
+// resolveEventsTarget returns params limiting events to the trigger if the
+// target is the trigger identifier or to hosts matching the target, ok is
+// false when no hosts match.
+func resolveEventsTarget(
+	ctx context.Context,
+	client *zabbix.Zabbix,
+	target string,
+	params zabbix.Params,
+) (resolved zabbix.Params, ok bool, err error) {
+	resolved = zabbix.Params{}
+	for key, value := range params {
+		resolved[key] = value
+	}
+
+	if reTriggerID.MatchString(target) {
+		resolved["objectids"] = target
+		return resolved, true, nil
+	}
+
+	hosts, err := client.GetHosts(ctx, zabbix.Params{
+		"search": zabbix.Params{
+			"name": target,
+		},
+		"searchWildcardsEnabled": "1",
+		"output":                 []string{"hostid"},
+	})
+	if err != nil {
+		return nil, false, karma.Format(
+			err,
+			"can't obtain zabbix hosts",
+		)
+	}
+
+	if len(hosts) == 0 {
+		return nil, false, nil
+	}
+
+	identifiers := []string{}
+	for _, host := range hosts {
+		identifiers = append(identifiers, host.ID)
+	}
+
+	resolved["hostids"] = identifiers
+
+	return resolved, true, nil
+}
+
+func sortEventsByClock(rows []eventRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, _ := strconv.ParseInt(rows[i].event.Clock, 10, 64)
+		b, _ := strconv.ParseInt(rows[j].event.Clock, 10, 64)
+
+		return a < b
+	})
+}
+
+func parseEventsParams(args map[string]interface{}) (zabbix.Params, error) {
+	var (
+		since, _ = args["--since"].(string)
+		until, _ = args["--until"].(string)
+		err      error
+	)
+
+	params := zabbix.Params{}
+
+	if since == "" {
+		since = defaultSince
+	}
+
+	params["time_from"], err = parseDateTime(since)
+	if err != nil {
+		return nil, err
+	}
+
+	if until != "" {
+		params["time_till"], err = parseDateTime(until)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveEventsTarget(t *testing.T) {
+	test := assert.New(t)
+
+	testcases := []struct {
+		target string
+		linked bool
+		params zabbix.Params
+		ok     bool
+	}{
+		{
+			target: "13579",
+			params: zabbix.Params{"time_from": 1, "objectids": "13579"},
+			ok:     true,
+		},
+		{
+			target: "db*",
+			linked: true,
+			params: zabbix.Params{
+				"time_from": 1,
+				"hostids":   []string{"3", "4"},
+			},
+			ok: true,
+		},
+		{
+			target: "web*",
+			ok:     false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		testserver := newHostFiltersTestServer(t, testcase.linked)
+
+		client, err := zabbix.NewZabbix(context.Background(), zabbix.Options{
+			Address: testserver.URL,
+			Token:   "token",
+		})
+		if !test.NoError(err) {
+			testserver.Close()
+			return
+		}
+
+		params := zabbix.Params{"time_from": 1}
+
+		resolved, ok, err := resolveEventsTarget(
+			context.Background(), client, testcase.target, params,
+		)
+
+		testserver.Close()
+
+		test.NoError(err)
+		test.Equal(testcase.ok, ok)
+		test.Equal(zabbix.Params{"time_from": 1}, params)
+
+		if testcase.ok {
+			test.Equal(testcase.params, resolved)
+		}
+	}
+}
@@ -1,32 +1,45 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
+	"github.com/lasseoe/zabbixctl/zabbix"
 	"github.com/olekukonko/tablewriter"
 	karma "github.com/reconquest/karma-go"
 )
 
+var hostColumns = []column[zabbix.Host]{
+	{"id", func(host zabbix.Host) string { return host.ID }},
+	{"name", func(host zabbix.Host) string { return host.Name }},
+}
+
 func handleHosts(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
 ) error {
 	var (
-		hostnames, _  = parseSearchQuery(args["<pattern>"].([]string))
+		hostnames, _  = parseSearchQuery(args)
 		removeHost, _ = args["--remove"].(string)
 
 		err               error
-		hostsTable, hosts []Host
+		hostsTable, hosts []zabbix.Host
 	)
 
 	destiny := karma.Describe("method", "handleHosts")
 
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
 	switch {
 	case removeHost != "":
 
-		err = handleRemoveHosts(zabbix, config, args)
+		err = handleRemoveHosts(ctx, client, config, args)
 		if err != nil {
 			return destiny.Describe(
 				"error", err,
@@ -38,7 +51,7 @@ func handleHosts(
 	default:
 
 		for _, hostname := range hostnames {
-			hosts, err = searchHosts(zabbix, hostname)
+			hosts, err = searchHosts(ctx, client, hostname)
 			if err != nil {
 				return destiny.Describe(
 					"error", err,
@@ -51,6 +64,11 @@ func handleHosts(
 			hostsTable = append(hostsTable, hosts...)
 
 		}
+
+		if output.custom() {
+			return printRows(output, hostColumns, hostsTable)
+		}
+
 		if len(hostsTable) > 0 {
 			err = printHostsTable(hostsTable)
 			if err != nil {
@@ -63,7 +81,8 @@ func handleHosts(
 }
 
 func handleRemoveHosts(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
 ) error {
@@ -72,7 +91,7 @@ func handleRemoveHosts(
 		confirmation  = !args["--noconfirm"].(bool)
 
 		err   error
-		hosts []Host
+		hosts []zabbix.Host
 	)
 
 	destiny := karma.Describe(
@@ -81,7 +100,7 @@ func handleRemoveHosts(
 		"hostname", removeHost,
 	)
 
-	hosts, err = searchHosts(zabbix, removeHost)
+	hosts, err = searchHosts(ctx, client, removeHost)
 	if err != nil {
 		return destiny.Describe(
 			"error", err,
@@ -114,17 +133,17 @@ func handleRemoveHosts(
 		":: Requesting for removing host",
 		func() error {
 			payload := []string{hosts[0].ID}
-			_, err = zabbix.RemoveHosts(payload)
+			_, err = client.RemoveHosts(ctx, payload)
 			return err
 		},
 	)
 	return err
 }
 
-func searchHosts(zabbix *Zabbix, hostname string) ([]Host, error) {
+func searchHosts(ctx context.Context, client *zabbix.Zabbix, hostname string) ([]zabbix.Host, error) {
 
 	var (
-		hosts []Host
+		hosts []zabbix.Host
 		err   error
 	)
 
@@ -132,8 +151,8 @@ func searchHosts(zabbix *Zabbix, hostname string) ([]Host, error) {
 		return hosts, nil
 	}
 
-	params := Params{
-		"search": Params{
+	params := zabbix.Params{
+		"search": zabbix.Params{
 			"name": hostname,
 		},
 		"output": []string{
@@ -145,7 +164,7 @@ func searchHosts(zabbix *Zabbix, hostname string) ([]Host, error) {
 	err = withSpinner(
 		":: Requesting information about hosts",
 		func() error {
-			hosts, err = zabbix.GetHosts(params)
+			hosts, err = client.GetHosts(ctx, params)
 			return err
 		},
 	)
@@ -153,7 +172,7 @@ func searchHosts(zabbix *Zabbix, hostname string) ([]Host, error) {
 	return hosts, err
 }
 
-func printHostsTable(hosts []Host) error {
+func printHostsTable(hosts []zabbix.Host) error {
 
 	var lines = [][]string{}
 
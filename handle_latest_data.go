@@ -1,171 +1,293 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"text/tabwriter"
 
+	"github.com/lasseoe/zabbixctl/zabbix"
 	"github.com/reconquest/karma-go"
 )
 
+// serverLatestData is latest data of hosts retrieved from a single server.
+type serverLatestData struct {
+	Server
+
+	hosts     map[string]zabbix.Host
+	items     []zabbix.Item
+	webchecks []zabbix.HTTPTest
+}
+
+// itemOutput is an item in the machine-readable output.
+type itemOutput struct {
+	Server string `json:"server,omitempty"`
+	Host   string `json:"host"`
+	zabbix.Item
+}
+
+// httpTestOutput is a web scenario in the machine-readable output.
+type httpTestOutput struct {
+	Server string `json:"server,omitempty"`
+	Host   string `json:"host"`
+	zabbix.HTTPTest
+}
+
+// latestDataOutput is an item or a web scenario in the latest data listing.
+type latestDataOutput interface {
+	values() latestDataValues
+}
+
+// latestDataValues are values of the latest data listing columns.
+type latestDataValues struct {
+	server, host, id, kind, name, time, value string
+}
+
+func (output *itemOutput) values() latestDataValues {
+	return latestDataValues{
+		server: output.Server,
+		host:   output.Host,
+		id:     output.ID,
+		kind:   output.Type.String(),
+		name:   output.Format(),
+		time:   output.DateTime(),
+		value:  output.LastValue,
+	}
+}
+
+func (output *httpTestOutput) values() latestDataValues {
+	return latestDataValues{
+		server: output.Server,
+		host:   output.Host,
+		id:     output.ID,
+		kind:   "scenario",
+		name:   output.Format(),
+		time:   output.DateTime(),
+	}
+}
+
+var latestDataColumns = []column[latestDataOutput]{
+	{"server", func(output latestDataOutput) string { return output.values().server }},
+	{"host", func(output latestDataOutput) string { return output.values().host }},
+	{"id", func(output latestDataOutput) string { return output.values().id }},
+	{"type", func(output latestDataOutput) string { return output.values().kind }},
+	{"name", func(output latestDataOutput) string { return output.values().name }},
+	{"time", func(output latestDataOutput) string { return output.values().time }},
+	{"value", func(output latestDataOutput) string { return output.values().value }},
+}
+
 func handleLatestData(
-	zabbix *Zabbix,
+	ctx context.Context,
+	servers []Server,
 	config *Config,
 	args map[string]interface{},
 ) error {
 	var (
-		hostnames, pattern = parseSearchQuery(args["<pattern>"].([]string))
-		graphs             = args["--graph"].(bool)
-		stackedGraph       = args["--stacked"].(bool)
-		normalGraph        = args["--normal"].(bool)
-		table              = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+		hostnames, query = parseSearchQuery(args)
+		graphs           = args["--graph"].(bool)
+		stackedGraph     = args["--stacked"].(bool)
+		normalGraph      = args["--normal"].(bool)
+		allContexts      = args["--all-contexts"].(bool)
+		outputs          = []latestDataOutput{}
+		table            = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
 	)
 
 	if len(hostnames) == 0 {
 		return errors.New("no hostname specified")
 	}
 
-	var hosts []Host
-	var err error
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*serverLatestData, len(servers))
+	for index, server := range servers {
+		results[index] = &serverLatestData{Server: server}
+	}
+
+	var errs []error
 
 	err = withSpinner(
-		":: Requesting information about hosts",
+		":: Requesting information about hosts items & web scenarios",
 		func() error {
-			hosts, err = zabbix.GetHosts(Params{
-				"monitored_hosts":         "1",
-				"with_items":              "1",
-				"with_monitored_items":    "1",
-				"with_monitored_triggers": "1",
-				"search": Params{
-					"name": hostnames,
-				},
-				"searchWildcardsEnabled": "1",
-				"output": []string{
-					"host",
-				},
+			errs = fanOut(len(results), func(index int) error {
+				return getLatestData(ctx, results[index], hostnames)
 			})
-			return err
+
+			return nil
 		},
 	)
-
+	if err == nil {
+		results, err = skipFailed(servers, results, errs, "can't obtain latest data")
+	}
 	if err != nil {
-		return karma.Format(
-			err,
-			"can't obtain zabbix hosts",
-		)
+		return err
 	}
 
-	var (
-		identifiers = []string{}
-		hash        = map[string]Host{}
-	)
-
-	for _, host := range hosts {
-		identifiers = append(identifiers, host.ID)
-		hash[host.ID] = host
-	}
+	for _, result := range results {
+		var (
+			prefix         string
+			server         string
+			matchedItemIDs = []string{}
+		)
 
-	debugf("* hosts identifiers: %s", identifiers)
+		if allContexts {
+			prefix = result.Name + "\t"
+			server = result.Name
+		}
 
-	var (
-		items     []Item
-		webchecks []HTTPTest
-	)
+		for _, item := range result.items {
+			line := fmt.Sprintf(
+				"%s\t%s\t%s\t%s\t%-10s",
+				result.hosts[item.HostID].Name, item.Type.String(), item.Format(),
+				item.DateTime(), item.LastValue,
+			)
 
-	err = withSpinner(
-		":: Requesting information about hosts items & web scenarios",
-		func() error {
-			errs := make(chan error)
+			if !query.match(line) {
+				continue
+			}
 
-			go func() {
-				var giErr error
+			matchedItemIDs = append(matchedItemIDs, item.ID)
 
-				items, giErr = zabbix.GetItems(Params{
-					"hostids":  identifiers,
-					"webitems": "1",
+			if output.custom() {
+				outputs = append(outputs, &itemOutput{
+					Server: server,
+					Host:   result.hosts[item.HostID].Name,
+					Item:   item,
 				})
 
-				errs <- giErr
-			}()
+				continue
+			}
 
-			go func() {
-				var ghErr error
+			fmt.Fprint(table, prefix+line)
 
-				webchecks, ghErr = zabbix.GetHTTPTests(Params{
-					"hostids":     identifiers,
-					"expandName":  "1",
-					"selectSteps": "extend",
-				})
+			if graphs {
+				fmt.Fprintf(table, "\t%s", result.Client.GetGraphURL(item.ID))
+			}
+
+			fmt.Fprint(table, "\n")
+		}
 
-				errs <- ghErr
-			}()
+		for _, check := range result.webchecks {
+			line := fmt.Sprintf(
+				"%s\t%s\t%s",
+				result.hosts[check.HostID].Name, `scenario`, check.Format(),
+			)
 
-			for _, err := range []error{<-errs, <-errs} {
-				if err != nil {
-					return err
-				}
+			if !query.match(line) {
+				continue
 			}
 
-			return nil
-		},
-	)
+			if output.custom() {
+				outputs = append(outputs, &httpTestOutput{
+					Server:   server,
+					Host:     result.hosts[check.HostID].Name,
+					HTTPTest: check,
+				})
+
+				continue
+			}
+
+			fmt.Fprintln(table, prefix+line)
+		}
+
+		switch {
+		case stackedGraph:
+			fmt.Println(result.Client.GetStackedGraphURL(matchedItemIDs))
+
+		case normalGraph:
+			fmt.Println(result.Client.GetNormalGraphURL(matchedItemIDs))
+		}
+	}
+
+	switch {
+	case stackedGraph, normalGraph:
+
+	case output.custom():
+		return printRows(output, latestDataColumns, outputs)
+
+	default:
+		err = table.Flush()
+		if err != nil {
+			debugf("Error: %+v", err)
+		}
+	}
+
+	return nil
+}
 
+func getLatestData(
+	ctx context.Context,
+	result *serverLatestData,
+	hostnames []string,
+) error {
+	hosts, err := result.Client.GetHosts(ctx, zabbix.Params{
+		"monitored_hosts":         "1",
+		"with_items":              "1",
+		"with_monitored_items":    "1",
+		"with_monitored_triggers": "1",
+		"search": zabbix.Params{
+			"name": hostnames,
+		},
+		"searchWildcardsEnabled": "1",
+		"output": []string{
+			"host",
+		},
+	})
 	if err != nil {
 		return karma.Format(
 			err,
-			"can't obtain zabbix items",
+			"can't obtain zabbix hosts",
 		)
 	}
 
-	var matchedItemIDs = []string{}
-
-	for _, item := range items {
-		line := fmt.Sprintf(
-			"%s\t%s\t%s\t%s\t%-10s",
-			hash[item.HostID].Name, item.Type.String(), item.Format(),
-			item.DateTime(), item.LastValue,
-		)
+	var (
+		identifiers = []string{}
+		hash        = map[string]zabbix.Host{}
+	)
 
-		if pattern != "" && !matchPattern(pattern, line) {
-			continue
-		}
+	for _, host := range hosts {
+		identifiers = append(identifiers, host.ID)
+		hash[host.ID] = host
+	}
 
-		fmt.Fprint(table, line)
+	debugf("* hosts identifiers: %s", identifiers)
 
-		if graphs {
-			fmt.Fprintf(table, "\t%s", zabbix.GetGraphURL(item.ID))
-		}
+	result.hosts = hash
 
-		fmt.Fprint(table, "\n")
+	errs := make(chan error)
 
-		matchedItemIDs = append(matchedItemIDs, item.ID)
-	}
+	go func() {
+		var giErr error
 
-	for _, check := range webchecks {
-		line := fmt.Sprintf(
-			"%s\t%s\t%s",
-			hash[check.HostID].Name, `scenario`, check.Format(),
-		)
+		result.items, giErr = result.Client.GetItems(ctx, zabbix.Params{
+			"hostids":  identifiers,
+			"webitems": "1",
+		})
 
-		if pattern != "" && !matchPattern(pattern, line) {
-			continue
-		}
+		errs <- giErr
+	}()
 
-		fmt.Fprintln(table, line)
-	}
+	go func() {
+		var ghErr error
 
-	switch {
-	case stackedGraph:
-		fmt.Println(zabbix.GetStackedGraphURL(matchedItemIDs))
+		result.webchecks, ghErr = result.Client.GetHTTPTests(ctx, zabbix.Params{
+			"hostids":     identifiers,
+			"expandName":  "1",
+			"selectSteps": "extend",
+		})
 
-	case normalGraph:
-		fmt.Println(zabbix.GetNormalGraphURL(matchedItemIDs))
+		errs <- ghErr
+	}()
 
-	default:
-		err = table.Flush()
+	for _, err := range []error{<-errs, <-errs} {
 		if err != nil {
-			debugf("Error: %+v", err)
+			return karma.Format(
+				err,
+				"can't obtain zabbix items",
+			)
 		}
 	}
 
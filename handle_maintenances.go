@@ -2,18 +2,21 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/lasseoe/zabbixctl/zabbix"
 	"github.com/olekukonko/tablewriter"
 	karma "github.com/reconquest/karma-go"
 )
 
 func handleMaintenances(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
 ) error {
@@ -23,7 +26,7 @@ func handleMaintenances(
 		addMaintenance, _    = args["--add"].(string)
 		removeMaintenance, _ = args["--remove"].(string)
 
-		maintenances []Maintenance
+		maintenances []zabbix.Maintenance
 	)
 
 	destiny := karma.Describe("method", "handleMaintenances")
@@ -33,9 +36,10 @@ func handleMaintenances(
 	case addMaintenance != "":
 
 		maintenances, err = searchMaintenances(
-			zabbix,
-			Params{
-				"search": Params{
+			ctx,
+			client,
+			zabbix.Params{
+				"search": zabbix.Params{
 					"name": addMaintenance,
 				},
 				"selectGroups": "extend",
@@ -52,17 +56,18 @@ func handleMaintenances(
 		switch len(maintenances) {
 
 		case 0:
-			err = handleAddMaintenance(zabbix, config, args)
+			err = handleAddMaintenance(ctx, client, config, args)
 		case 1:
-			err = handleUpdateMaintenance(zabbix, config, args, maintenances)
+			err = handleUpdateMaintenance(ctx, client, config, args, maintenances)
 		}
 
 	case removeMaintenance != "":
 
 		maintenances, err = searchMaintenances(
-			zabbix,
-			Params{
-				"search": Params{
+			ctx,
+			client,
+			zabbix.Params{
+				"search": zabbix.Params{
 					"name": removeMaintenance,
 				},
 				"selectGroups": "extend",
@@ -75,10 +80,10 @@ func handleMaintenances(
 				"can't obtain zabbix maintenances",
 			)
 		}
-		err = handleRemoveMaintenance(zabbix, config, args, maintenances)
+		err = handleRemoveMaintenance(ctx, client, config, args, maintenances)
 
 	default:
-		err = handleListMaintenances(zabbix, config, args)
+		err = handleListMaintenances(ctx, client, config, args)
 	}
 
 	if err != nil {
@@ -93,21 +98,22 @@ func handleMaintenances(
 }
 
 func handleAddMaintenance(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
 ) error {
 
 	var (
-		hostnames, _      = parseSearchQuery(args["<pattern>"].([]string))
+		hostnames, _      = parseSearchQuery(args)
 		addMaintenance, _ = args["--add"].(string)
 		confirmation      = !args["--noconfirm"].(bool)
 		fromStdin         = args["--read-stdin"].(bool)
 
 		hostids   = []string{}
 		uniqHosts = make(map[string]bool)
-		hosts     []Host
-		params    Params
+		hosts     []zabbix.Host
+		params    zabbix.Params
 	)
 
 	destiny := karma.Describe(
@@ -124,7 +130,7 @@ func handleAddMaintenance(
 	}
 
 	for _, hostname := range hostnames {
-		foundHosts, err := searchHosts(zabbix, hostname)
+		foundHosts, err := searchHosts(ctx, client, hostname)
 		if err != nil {
 			return destiny.Describe(
 				"error", err,
@@ -164,17 +170,17 @@ func handleAddMaintenance(
 		)
 	}
 
-	params = Params{
+	params = zabbix.Params{
 		"name":         addMaintenance,
 		"active_since": timeperiod.StartDate,
 		"active_till":  activeTill,
 		"hostids":      hostids,
-		"timeperiods":  []Timeperiod{timeperiod},
+		"timeperiods":  []zabbix.Timeperiod{timeperiod},
 	}
 	err = withSpinner(
 		":: Requesting for create to specified maintenance",
 		func() error {
-			_, err = zabbix.CreateMaintenance(params)
+			_, err = client.CreateMaintenance(ctx, params)
 			return err
 		},
 	)
@@ -196,22 +202,23 @@ func handleAddMaintenance(
 }
 
 func handleUpdateMaintenance(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
-	maintenances []Maintenance,
+	maintenances []zabbix.Maintenance,
 ) error {
 
 	var (
-		hostnames, _      = parseSearchQuery(args["<pattern>"].([]string))
+		hostnames, _      = parseSearchQuery(args)
 		addMaintenance, _ = args["--add"].(string)
 		confirmation      = !args["--noconfirm"].(bool)
 		fromStdin, _      = args["--read-stdin"].(bool)
 
 		hostids   = []string{}
 		uniqHosts = make(map[string]bool)
-		hosts     []Host
-		params    Params
+		hosts     []zabbix.Host
+		params    zabbix.Params
 	)
 
 	destiny := karma.Describe(
@@ -228,7 +235,7 @@ func handleUpdateMaintenance(
 	}
 
 	for _, hostname := range hostnames {
-		foundHosts, err := searchHosts(zabbix, hostname)
+		foundHosts, err := searchHosts(ctx, client, hostname)
 		if err != nil {
 			return destiny.Describe(
 				"error", err,
@@ -273,14 +280,14 @@ func handleUpdateMaintenance(
 		}
 	}
 
-	params = Params{
+	params = zabbix.Params{
 		"maintenanceid": maintenance.ID,
 		"hostids":       hostids,
 	}
 	err = withSpinner(
 		":: Requesting for updating hosts to specified maintenance",
 		func() error {
-			_, err = zabbix.UpdateMaintenance(params)
+			_, err = client.UpdateMaintenance(ctx, params)
 			return err
 		},
 	)
@@ -295,19 +302,20 @@ func handleUpdateMaintenance(
 }
 
 func handleRemoveMaintenance(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
-	maintenances []Maintenance,
+	maintenances []zabbix.Maintenance,
 ) error {
 
 	var (
 		removeMaintenance, _ = args["--remove"].(string)
 		confirmation         = !args["--noconfirm"].(bool)
 
-		pattern string
-		err     error
-		extend  = true
+		query  searchQuery
+		err    error
+		extend = true
 	)
 
 	destiny := karma.Describe(
@@ -322,7 +330,7 @@ func handleRemoveMaintenance(
 		)
 	}
 
-	err = printMaintenancesTable(maintenances, pattern, extend)
+	err = printMaintenancesTable(maintenances, query, extend)
 	if err != nil {
 		debugf("Error: %+v", err)
 	}
@@ -338,7 +346,7 @@ func handleRemoveMaintenance(
 		func() error {
 			maintenance := maintenances[0].ID
 			payload := []string{maintenance}
-			_, err = zabbix.RemoveMaintenance(payload)
+			_, err = client.RemoveMaintenance(ctx, payload)
 			return err
 		},
 	)
@@ -352,30 +360,71 @@ func handleRemoveMaintenance(
 	return nil
 }
 
+var maintenanceColumns = []column[zabbix.Maintenance]{
+	{"id", func(maintenance zabbix.Maintenance) string { return maintenance.ID }},
+	{"name", func(maintenance zabbix.Maintenance) string {
+		return maintenance.Name
+	}},
+	{"since", func(maintenance zabbix.Maintenance) string {
+		return maintenance.GetDateTime(maintenance.Since)
+	}},
+	{"till", func(maintenance zabbix.Maintenance) string {
+		return maintenance.GetDateTime(maintenance.Till)
+	}},
+	{"status", func(maintenance zabbix.Maintenance) string {
+		return maintenance.GetStatus()
+	}},
+	{"data", func(maintenance zabbix.Maintenance) string {
+		return maintenance.GetTypeCollect()
+	}},
+	{"groups", func(maintenance zabbix.Maintenance) string {
+		names := []string{}
+		for _, group := range maintenance.Groups {
+			names = append(names, group.Name)
+		}
+
+		return strings.Join(names, " ")
+	}},
+	{"hosts", func(maintenance zabbix.Maintenance) string {
+		names := []string{}
+		for _, host := range maintenance.Hosts {
+			names = append(names, host.Name)
+		}
+
+		return strings.Join(names, " ")
+	}},
+}
+
 func handleListMaintenances(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
 ) error {
 
 	var (
-		hostnames, pattern = parseSearchQuery(args["<pattern>"].([]string))
+		hostnames, query = parseSearchQuery(args)
 
 		hostids      = []string{}
 		groupids     = []string{}
 		extend       bool
 		err          error
-		hosts        []Host
-		groups       []Group
-		maintenances []Maintenance
+		hosts        []zabbix.Host
+		groups       []zabbix.Group
+		maintenances []zabbix.Maintenance
 	)
 
 	destiny := karma.Describe("method", "ListMaintenances")
 
-	params := Params{}
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
+	params := zabbix.Params{}
 
 	for _, hostname := range hostnames {
-		hosts, err = searchHosts(zabbix, hostname)
+		hosts, err = searchHosts(ctx, client, hostname)
 		if err != nil {
 			return destiny.Describe(
 				"error", err,
@@ -396,7 +445,7 @@ func handleListMaintenances(
 		err = withSpinner(
 			":: Requesting information about groups",
 			func() error {
-				groups, err = zabbix.GetGroups(Params{
+				groups, err = client.GetGroups(ctx, zabbix.Params{
 					"output":       "extend",
 					"selectGroups": "extend",
 					"hostids":      hostids,
@@ -420,13 +469,14 @@ func handleListMaintenances(
 		params["groupids"] = groupids
 	}
 
-	if len(hostnames) > 0 || pattern != "" {
+	// groups and hosts columns need maintenances extended as well
+	if len(hostnames) > 0 || !query.empty() || output.custom() {
 		extend = true
 		params["selectGroups"] = "extend"
 		params["selectHosts"] = "extend"
 	}
 
-	maintenances, err = searchMaintenances(zabbix, params)
+	maintenances, err = searchMaintenances(ctx, client, params)
 	if err != nil {
 		return destiny.Describe(
 			"error", err,
@@ -435,20 +485,33 @@ func handleListMaintenances(
 		)
 	}
 
-	err = printMaintenancesTable(maintenances, pattern, extend)
+	if output.custom() {
+		matched := []zabbix.Maintenance{}
+		for _, maintenance := range maintenances {
+			if !query.match(maintenance.GetString()) {
+				continue
+			}
+
+			matched = append(matched, maintenance)
+		}
+
+		return printRows(output, maintenanceColumns, matched)
+	}
+
+	err = printMaintenancesTable(maintenances, query, extend)
 	if err != nil {
 		debugf("Error: %+v", err)
 	}
 	return nil
 }
 
-func printMaintenancesTable(maintenances []Maintenance, pattern string,
+func printMaintenancesTable(maintenances []zabbix.Maintenance, query searchQuery,
 	extend bool) error {
 
 	var lines = [][]string{}
 
 	for _, maintenance := range maintenances {
-		if pattern != "" && !matchPattern(pattern, maintenance.GetString()) {
+		if !query.match(maintenance.GetString()) {
 			continue
 		}
 
@@ -542,14 +605,14 @@ func printMaintenancesTable(maintenances []Maintenance, pattern string,
 	return nil
 }
 
-func searchMaintenances(zabbix *Zabbix, extend Params) ([]Maintenance, error) {
+func searchMaintenances(ctx context.Context, client *zabbix.Zabbix, extend zabbix.Params) ([]zabbix.Maintenance, error) {
 
 	var (
-		maintenances []Maintenance
+		maintenances []zabbix.Maintenance
 		err          error
 	)
 
-	params := Params{
+	params := zabbix.Params{
 		"output":                 "extend",
 		"selectTimeperiods":      "extend",
 		"searchWildcardsEnabled": "1",
@@ -562,7 +625,7 @@ func searchMaintenances(zabbix *Zabbix, extend Params) ([]Maintenance, error) {
 	err = withSpinner(
 		":: Requesting information about maintenances",
 		func() error {
-			maintenances, err = zabbix.GetMaintenances(params)
+			maintenances, err = client.GetMaintenances(ctx, params)
 			return err
 		},
 	)
@@ -571,14 +634,14 @@ func searchMaintenances(zabbix *Zabbix, extend Params) ([]Maintenance, error) {
 
 func createTimeperiod(
 	args map[string]interface{},
-) (Timeperiod, string, error) {
+) (zabbix.Timeperiod, string, error) {
 
 	var (
 		startDate, _ = args["--start"].(string)
 		endDate, _   = args["--end"].(string)
 		period, _    = args["--period"].(string)
 
-		timeperiod Timeperiod
+		timeperiod zabbix.Timeperiod
 		activeTill int64
 	)
 
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+// serverProblems are problems retrieved from a single server.
+type serverProblems struct {
+	Server
+
+	problems []zabbix.Problem
+}
+
+// problemOutput is a problem in the machine-readable output.
+type problemOutput struct {
+	Server string `json:"server,omitempty"`
+	zabbix.Problem
+}
+
+// Host returns the name of the problem host.
+func (output *problemOutput) Host() string {
+	return output.GetHostName()
+}
+
+// problemRow is a problem listed in the output along with its server.
+type problemRow struct {
+	*serverProblems
+
+	problem zabbix.Problem
+}
+
+var problemColumns = []column[*problemOutput]{
+	{"server", func(output *problemOutput) string { return output.Server }},
+	{"id", func(output *problemOutput) string { return output.EventID }},
+	{"triggerid", func(output *problemOutput) string { return output.ObjectID }},
+	{"time", func(output *problemOutput) string { return output.DateTime() }},
+	{"duration", func(output *problemOutput) string {
+		return output.Duration()
+	}},
+	{"severity", func(output *problemOutput) string {
+		return output.Severity().String()
+	}},
+	{"status", func(output *problemOutput) string {
+		return output.StatusProblem()
+	}},
+	{"ack", func(output *problemOutput) string {
+		return output.StatusAcknowledge()
+	}},
+	{"host", func(output *problemOutput) string { return output.Host() }},
+	{"name", func(output *problemOutput) string { return output.Name }},
+	{"tags", func(output *problemOutput) string { return output.TagsString() }},
+	{"ackuser", func(output *problemOutput) string {
+		if last := output.LastAcknowledge(); last != nil {
+			return last.User()
+		}
+
+		return ""
+	}},
+	{"ackmessage", func(output *problemOutput) string {
+		if last := output.LastAcknowledge(); last != nil {
+			return last.Message
+		}
+
+		return ""
+	}},
+}
+
+func handleProblems(
+	ctx context.Context,
+	servers []Server,
+	config *Config,
+	args map[string]interface{},
+) error {
+	var (
+		words, query = parseSearchQuery(args)
+		confirmation = !args["--noconfirm"].(bool)
+		edit         = args["--edit"].(bool)
+		extended     = args["--extended"].(int) > 0
+		allContexts  = args["--all-contexts"].(bool)
+		order        = args["--order"].(string)
+		filters      = parseHostFilters(args)
+		outputs      = []*problemOutput{}
+
+		table = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	)
+
+	if len(words) > 0 {
+		return fmt.Errorf(
+			"unexpected command line token '%s', "+
+				"use '/%s' for searching problems",
+			words[0], words[0],
+		)
+	}
+
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
+	params, err := parseProblemsParams(args)
+	if err != nil {
+		return err
+	}
+
+	acknowledge, act, err := parseAcknowledgeOptions(args)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*serverProblems, len(servers))
+	for index, server := range servers {
+		results[index] = &serverProblems{Server: server}
+	}
+
+	var errs []error
+
+	err = withSpinner(
+		":: Requesting information about problems",
+		func() error {
+			errs = fanOut(len(results), func(index int) error {
+				params, err := filters.resolve(
+					ctx, results[index].Client, params,
+				)
+				if err != nil {
+					return err
+				}
+
+				results[index].problems, err = results[index].Client.GetProblems(
+					ctx, params,
+				)
+
+				return err
+			})
+
+			return nil
+		},
+	)
+	if err == nil {
+		results, err = skipFailed(servers, results, errs, "can't obtain zabbix problems")
+	}
+	if err != nil {
+		return karma.Format(
+			err,
+			"can't obtain zabbix problems",
+		)
+	}
+
+	rows := []problemRow{}
+	for _, result := range results {
+		for _, problem := range result.problems {
+			rows = append(rows, problemRow{
+				serverProblems: result,
+				problem:        problem,
+			})
+		}
+	}
+
+	if len(results) > 1 {
+		sortProblemsByClock(rows, order)
+	}
+
+	identifiers := map[*serverProblems][]string{}
+	for _, row := range rows {
+		problem := row.problem
+
+		if !query.match(problem.String()) {
+			continue
+		}
+
+		identifiers[row.serverProblems] = append(
+			identifiers[row.serverProblems],
+			problem.EventID,
+		)
+
+		if output.custom() {
+			entry := &problemOutput{Problem: problem}
+			if allContexts {
+				entry.Server = row.Name
+			}
+
+			outputs = append(outputs, entry)
+
+			continue
+		}
+
+		var prefix string
+		if allContexts {
+			prefix = row.Name + "\t"
+		}
+
+		fmt.Fprintf(
+			table,
+			"%s%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+			prefix,
+			problem.EventID, problem.DateTime(),
+			problem.Duration(),
+			problem.Severity(),
+			problem.StatusProblem(),
+			problem.StatusAcknowledge(),
+			problem.GetHostName(),
+			problem.Name,
+		)
+
+		if last := problem.LastAcknowledge(); last != nil && !extended {
+			fmt.Fprintf(table, "\t%s: %s", last.User(), last.Message)
+		}
+
+		fmt.Fprint(table, "\n")
+
+		if !extended {
+			continue
+		}
+
+		// details are printed under the name column
+		indent := prefix + strings.Repeat("\t", 7)
+
+		if len(problem.Tags) > 0 {
+			fmt.Fprintf(table, "%stags: %s\n", indent, problem.TagsString())
+		}
+
+		for _, update := range problem.Acknowledges {
+			if update.Message == "" {
+				continue
+			}
+
+			fmt.Fprintf(
+				table,
+				"%s%s %s: %s\n",
+				indent, update.DateTime(), update.User(), update.Message,
+			)
+		}
+	}
+
+	if output.custom() {
+		err = printRows(output, problemColumns, outputs)
+	} else {
+		err = table.Flush()
+	}
+	if err != nil {
+		return err
+	}
+
+	if !act {
+		return nil
+	}
+
+	events := []serverEvents{}
+	for _, result := range results {
+		if len(identifiers[result]) > 0 {
+			events = append(events, serverEvents{
+				Server:      result.Server,
+				identifiers: identifiers[result],
+			})
+		}
+	}
+
+	return updateEvents(ctx, events, acknowledge, edit, confirmation)
+}
+
+func sortProblemsByClock(rows []problemRow, order string) {
+	ascending := strings.EqualFold(order, "ASC")
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, _ := strconv.ParseInt(rows[i].problem.Clock, 10, 64)
+		b, _ := strconv.ParseInt(rows[j].problem.Clock, 10, 64)
+
+		if ascending {
+			return a < b
+		}
+
+		return a > b
+	})
+}
+
+func parseProblemsParams(args map[string]interface{}) (zabbix.Params, error) {
+	var (
+		severity   = args["-x"].(int)
+		onlyNotAck = args["--only-nack"].(bool)
+		recent     = args["--recent"].(bool)
+		since, _   = args["--since"].(string)
+		until, _   = args["--until"].(string)
+		order      = args["--order"].(string)
+		limit      = args["--limit"].(string)
+	)
+
+	if named, _ := args["--severity"].(string); named != "" && severity > 0 {
+		return nil, errors.New("-x and --severity are mutually exclusive")
+	}
+
+	params := zabbix.Params{
+		"sortorder": order,
+	}
+
+	if limit != "0" {
+		params["limit"] = limit
+	}
+
+	if severity > 0 {
+		severities := []int{}
+		for value := severity; value <= int(zabbix.SeverityDisaster); value++ {
+			severities = append(severities, value)
+		}
+
+		params["severities"] = severities
+	}
+
+	if onlyNotAck {
+		params["acknowledged"] = false
+	}
+
+	if recent {
+		params["recent"] = true
+	}
+
+	err := setTagFilters(params, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if since != "" {
+		params["time_from"], err = parseDateTime(since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if until != "" {
+		params["time_till"], err = parseDateTime(until)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return params, nil
+}
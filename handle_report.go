@@ -0,0 +1,637 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	reportByTrigger = "trigger"
+	reportByHost    = "host"
+
+	// reportPeriod is the least duration of the period named by --since,
+	// e.g. 'last week', which is reported as a whole when --until is not set
+	reportPeriod = 7*24*time.Hour - time.Second
+)
+
+// problemInterval is the time the trigger was in a problem state within the
+// report window, resolved is false when the problem lasts till the end of the
+// window.
+type problemInterval struct {
+	from     int64
+	till     int64
+	resolved bool
+}
+
+// triggerAvailability are problems of the trigger within the report window.
+type triggerAvailability struct {
+	server    string
+	id        string
+	host      string
+	name      string
+	severity  zabbix.Severity
+	problems  int
+	intervals []problemInterval
+}
+
+// serverAvailability are triggers of a single server which were in a problem
+// state within the report window, initial are triggers with events within the
+// window which were in a problem state already at its start.
+type serverAvailability struct {
+	Server
+
+	events  []zabbix.Event
+	ongoing []zabbix.Trigger
+	initial map[string]bool
+}
+
+// availabilityOutput is a row of the availability report, durations are in
+// seconds and the availability is in percents.
+type availabilityOutput struct {
+	Server       string  `json:"server,omitempty"`
+	TriggerID    string  `json:"triggerid,omitempty"`
+	Host         string  `json:"host"`
+	Name         string  `json:"name,omitempty"`
+	Severity     string  `json:"severity,omitempty"`
+	Problems     int     `json:"problems"`
+	ProblemTime  int64   `json:"problem_time"`
+	MTTR         int64   `json:"mttr"`
+	Availability float64 `json:"availability"`
+}
+
+var availabilityColumns = []column[*availabilityOutput]{
+	{"server", func(output *availabilityOutput) string { return output.Server }},
+	{"triggerid", func(output *availabilityOutput) string {
+		return output.TriggerID
+	}},
+	{"host", func(output *availabilityOutput) string { return output.Host }},
+	{"name", func(output *availabilityOutput) string { return output.Name }},
+	{"severity", func(output *availabilityOutput) string {
+		return output.Severity
+	}},
+	{"problems", func(output *availabilityOutput) string {
+		return strconv.Itoa(output.Problems)
+	}},
+	{"problemtime", func(output *availabilityOutput) string {
+		return formatReportDuration(output.ProblemTime)
+	}},
+	{"problemseconds", func(output *availabilityOutput) string {
+		return strconv.FormatInt(output.ProblemTime, 10)
+	}},
+	{"mttr", func(output *availabilityOutput) string {
+		return formatReportDuration(output.MTTR)
+	}},
+	{"mttrseconds", func(output *availabilityOutput) string {
+		return strconv.FormatInt(output.MTTR, 10)
+	}},
+	{"availability", func(output *availabilityOutput) string {
+		return strconv.FormatFloat(output.Availability, 'f', 3, 64)
+	}},
+}
+
+func handleReport(
+	ctx context.Context,
+	servers []Server,
+	config *Config,
+	args map[string]interface{},
+) error {
+	if !args["availability"].(bool) {
+		return errors.New("unexpected report, expected: availability")
+	}
+
+	return handleAvailabilityReport(ctx, servers, args)
+}
+
+func handleAvailabilityReport(
+	ctx context.Context,
+	servers []Server,
+	args map[string]interface{},
+) error {
+	var (
+		words, query = parseSearchQuery(args)
+		allContexts  = args["--all-contexts"].(bool)
+		by           = args["--by"].(string)
+		filters      = parseHostFilters(args)
+	)
+
+	if len(words) > 0 {
+		return fmt.Errorf(
+			"unexpected command line token '%s', "+
+				"use '/%s' for searching triggers",
+			words[0], words[0],
+		)
+	}
+
+	if by != reportByTrigger && by != reportByHost {
+		return fmt.Errorf(
+			"unexpected --by '%s', expected one of: %s, %s",
+			by, reportByTrigger, reportByHost,
+		)
+	}
+
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
+	from, till, err := parseReportWindow(args)
+	if err != nil {
+		return err
+	}
+
+	params := zabbix.Params{}
+
+	err = setTagFilters(params, args)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*serverAvailability, len(servers))
+	for index, server := range servers {
+		results[index] = &serverAvailability{Server: server}
+	}
+
+	var errs []error
+
+	err = withSpinner(
+		":: Requesting events for the report",
+		func() error {
+			errs = fanOut(len(results), func(index int) error {
+				params, err := filters.resolve(
+					ctx, results[index].Client, params,
+				)
+				if err != nil {
+					return err
+				}
+
+				return getAvailabilityEvents(ctx, results[index], params, from, till)
+			})
+
+			return nil
+		},
+	)
+	if err == nil {
+		results, err = skipFailed(servers, results, errs, "can't obtain zabbix events")
+	}
+	if err != nil {
+		return karma.Format(
+			err,
+			"can't obtain zabbix events",
+		)
+	}
+
+	triggers := []*triggerAvailability{}
+	for _, result := range results {
+		for _, trigger := range getTriggersAvailability(
+			result.events, result.ongoing, result.initial, from, till,
+		) {
+			if allContexts {
+				trigger.server = result.Name
+			}
+
+			if !query.match(trigger.host + " " + trigger.name) {
+				continue
+			}
+
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	var rows []*availabilityOutput
+	if by == reportByHost {
+		rows = getHostsAvailabilityRows(triggers, from, till)
+	} else {
+		rows = getTriggersAvailabilityRows(triggers, from, till)
+	}
+
+	if output.custom() {
+		return printRows(output, availabilityColumns, rows)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+
+	for _, row := range rows {
+		if allContexts {
+			fmt.Fprintf(table, "%s\t", row.Server)
+		}
+
+		if by == reportByTrigger {
+			fmt.Fprintf(
+				table,
+				"%s\t%s\t%s\t%s\t",
+				row.TriggerID, row.Severity, row.Host, row.Name,
+			)
+		} else {
+			fmt.Fprintf(table, "%s\t", row.Host)
+		}
+
+		fmt.Fprintf(
+			table,
+			"%d\t%s\t%s\t%.3f%%\n",
+			row.Problems,
+			formatReportDuration(row.ProblemTime),
+			formatReportDuration(row.MTTR),
+			row.Availability,
+		)
+	}
+
+	return table.Flush()
+}
+
+// parseReportWindow returns the window of the report, the period named by
+// --since is reported as a whole when --until isn't set, e.g. 'last month',
+// otherwise the window ends now.
+func parseReportWindow(args map[string]interface{}) (int64, int64, error) {
+	var (
+		since, _ = args["--since"].(string)
+		until, _ = args["--until"].(string)
+		now      = time.Now().Unix()
+	)
+
+	if since == "" {
+		since = defaultSince
+	}
+
+	from, till, err := parseDateRange(since)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch {
+	case until != "":
+		till, err = parseDateTime(until)
+		if err != nil {
+			return 0, 0, err
+		}
+	case time.Duration(till-from)*time.Second < reportPeriod:
+		till = now
+	default:
+		// the named period ends at its last second inclusively
+		till++
+	}
+
+	if till > now {
+		till = now
+	}
+
+	if till <= from {
+		return 0, 0, fmt.Errorf(
+			"the report window is empty: %s - %s",
+			time.Unix(from, 0).Format(zabbix.TimeFormat),
+			time.Unix(till, 0).Format(zabbix.TimeFormat),
+		)
+	}
+
+	return from, till, nil
+}
+
+// getAvailabilityEvents retrieves events within the window and triggers which
+// were in a problem state during the whole window. The state of triggers at
+// the start of the window is given by their last events before it, when the
+// first event within the window doesn't resolve a problem or the trigger has
+// no events within the window but changed after it.
+func getAvailabilityEvents(
+	ctx context.Context,
+	result *serverAvailability,
+	params zabbix.Params,
+	from, till int64,
+) error {
+	events := zabbix.Params{
+		"output": []string{
+			"eventid", "objectid", "clock", "value", "name", "severity",
+		},
+		"select_acknowledges": nil,
+		"select_alerts":       nil,
+		"time_from":           from,
+		"time_till":           till,
+	}
+
+	ongoing := zabbix.Params{
+		"filter":          zabbix.Params{"value": "1"},
+		"lastChangeTill":  from,
+		"skipDependent":   false,
+		"selectLastEvent": nil,
+		"selectFunctions": nil,
+	}
+
+	changed := zabbix.Params{
+		"lastChangeSince": till + 1,
+		"skipDependent":   false,
+		"selectLastEvent": nil,
+		"selectFunctions": nil,
+	}
+
+	for key, value := range params {
+		events[key] = value
+		ongoing[key] = value
+		changed[key] = value
+	}
+
+	var err error
+
+	result.events, err = result.Client.GetEvents(ctx, events)
+	if err != nil {
+		return err
+	}
+
+	result.ongoing, err = result.Client.GetTriggers(ctx, ongoing)
+	if err != nil {
+		return err
+	}
+
+	triggers, err := result.Client.GetTriggers(ctx, changed)
+	if err != nil {
+		return err
+	}
+
+	var (
+		identifiers = []string{}
+		first       = map[string]bool{}
+	)
+
+	// the first event of a problem doesn't mean the trigger was fine before,
+	// triggers can generate multiple problems
+	for _, event := range result.events {
+		if first[event.ObjectID] {
+			continue
+		}
+
+		first[event.ObjectID] = true
+
+		if event.Problem() {
+			identifiers = append(identifiers, event.ObjectID)
+		}
+	}
+
+	for _, trigger := range triggers {
+		if !first[trigger.ID] {
+			identifiers = append(identifiers, trigger.ID)
+		}
+	}
+
+	last, err := result.Client.GetLastEvents(ctx, identifiers, from-1)
+	if err != nil {
+		return err
+	}
+
+	problems := map[string]bool{}
+	for identifier, event := range last {
+		problems[identifier] = event.Problem()
+	}
+
+	result.initial = map[string]bool{}
+	for _, identifier := range identifiers {
+		if first[identifier] && problems[identifier] {
+			result.initial[identifier] = true
+		}
+	}
+
+	for _, trigger := range triggers {
+		if !first[trigger.ID] && problems[trigger.ID] {
+			result.ongoing = append(result.ongoing, trigger)
+		}
+	}
+
+	return nil
+}
+
+// getTriggersAvailability returns problem intervals of triggers within the
+// window, the problem is counted from the start of the window when the first
+// event of the trigger resolves it or the trigger is initial, i.e. it was in a
+// problem state at the start of the window.
+func getTriggersAvailability(
+	events []zabbix.Event,
+	ongoing []zabbix.Trigger,
+	initial map[string]bool,
+	from, till int64,
+) []*triggerAvailability {
+	var (
+		triggers = []*triggerAvailability{}
+		hash     = map[string]*triggerAvailability{}
+		started  = map[string]int64{}
+	)
+
+	for _, event := range events {
+		trigger, ok := hash[event.ObjectID]
+		if !ok {
+			trigger = &triggerAvailability{id: event.ObjectID}
+			hash[event.ObjectID] = trigger
+			triggers = append(triggers, trigger)
+
+			if !event.Problem() || initial[trigger.id] {
+				trigger.problems++
+				started[trigger.id] = from
+			}
+		}
+
+		trigger.host = event.GetHostName()
+		trigger.name = event.Name
+		trigger.severity = event.Severity()
+
+		clock, _ := strconv.ParseInt(event.Clock, 10, 64)
+
+		start, problem := started[trigger.id]
+
+		switch {
+		case event.Problem():
+			trigger.problems++
+
+			// several problems of the trigger overlap
+			if !problem {
+				started[trigger.id] = clock
+			}
+
+		case problem:
+			trigger.intervals = append(trigger.intervals, problemInterval{
+				from:     start,
+				till:     clock,
+				resolved: true,
+			})
+
+			delete(started, trigger.id)
+		}
+	}
+
+	for _, trigger := range triggers {
+		if start, ok := started[trigger.id]; ok {
+			trigger.intervals = append(trigger.intervals, problemInterval{
+				from: start,
+				till: till,
+			})
+		}
+	}
+
+	for _, trigger := range ongoing {
+		if _, ok := hash[trigger.ID]; ok {
+			continue
+		}
+
+		triggers = append(triggers, &triggerAvailability{
+			id:        trigger.ID,
+			host:      trigger.GetHostName(),
+			name:      trigger.Description,
+			severity:  trigger.Severity(),
+			problems:  1,
+			intervals: []problemInterval{{from: from, till: till}},
+		})
+	}
+
+	return triggers
+}
+
+func getTriggersAvailabilityRows(
+	triggers []*triggerAvailability,
+	from, till int64,
+) []*availabilityOutput {
+	rows := []*availabilityOutput{}
+
+	for _, trigger := range triggers {
+		row := newAvailabilityOutput(
+			trigger.problems, trigger.intervals, trigger.intervals, from, till,
+		)
+
+		row.Server = trigger.server
+		row.TriggerID = trigger.id
+		row.Host = trigger.host
+		row.Name = trigger.name
+		row.Severity = trigger.severity.String()
+
+		rows = append(rows, row)
+	}
+
+	sortAvailabilityRows(rows)
+
+	return rows
+}
+
+// getHostsAvailabilityRows returns availability of hosts, the host is in a
+// problem state while any of its triggers is.
+func getHostsAvailabilityRows(
+	triggers []*triggerAvailability,
+	from, till int64,
+) []*availabilityOutput {
+	type host struct {
+		server    string
+		name      string
+		problems  int
+		intervals []problemInterval
+	}
+
+	var (
+		hosts = []*host{}
+		hash  = map[string]*host{}
+	)
+
+	for _, trigger := range triggers {
+		key := trigger.server + "\x00" + trigger.host
+
+		entry, ok := hash[key]
+		if !ok {
+			entry = &host{server: trigger.server, name: trigger.host}
+			hash[key] = entry
+			hosts = append(hosts, entry)
+		}
+
+		entry.problems += trigger.problems
+		entry.intervals = append(entry.intervals, trigger.intervals...)
+	}
+
+	rows := []*availabilityOutput{}
+	for _, host := range hosts {
+		row := newAvailabilityOutput(
+			host.problems, mergeIntervals(host.intervals), host.intervals,
+			from, till,
+		)
+
+		row.Server = host.server
+		row.Host = host.name
+
+		rows = append(rows, row)
+	}
+
+	sortAvailabilityRows(rows)
+
+	return rows
+}
+
+// newAvailabilityOutput returns the row for the problem time of intervals,
+// the MTTR is the mean duration of resolved problems.
+func newAvailabilityOutput(
+	problems int,
+	intervals []problemInterval,
+	repairs []problemInterval,
+	from, till int64,
+) *availabilityOutput {
+	row := &availabilityOutput{Problems: problems}
+
+	for _, interval := range intervals {
+		row.ProblemTime += interval.till - interval.from
+	}
+
+	var (
+		resolved int64
+		repair   int64
+	)
+
+	for _, interval := range repairs {
+		if interval.resolved {
+			resolved++
+			repair += interval.till - interval.from
+		}
+	}
+
+	if resolved > 0 {
+		row.MTTR = repair / resolved
+	}
+
+	row.Availability = 100 * (1 - float64(row.ProblemTime)/float64(till-from))
+
+	return row
+}
+
+// mergeIntervals returns the union of intervals.
+func mergeIntervals(intervals []problemInterval) []problemInterval {
+	sorted := append([]problemInterval{}, intervals...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].from < sorted[j].from
+	})
+
+	merged := []problemInterval{}
+	for _, interval := range sorted {
+		last := len(merged) - 1
+		if last >= 0 && interval.from <= merged[last].till {
+			if interval.till > merged[last].till {
+				merged[last].till = interval.till
+			}
+
+			continue
+		}
+
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+func sortAvailabilityRows(rows []*availabilityOutput) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].ProblemTime > rows[j].ProblemTime
+	})
+}
+
+func formatReportDuration(seconds int64) string {
+	if seconds == 0 {
+		return "0s"
+	}
+
+	return zabbix.FormatDuration(time.Duration(seconds) * time.Second)
+}
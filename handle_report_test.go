@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReportEvent(trigger, host, value string, clock int64) zabbix.Event {
+	event := zabbix.Event{
+		ObjectID: trigger,
+		Value:    value,
+		Clock:    strconv.FormatInt(clock, 10),
+		Name:     "trigger " + trigger,
+		Priority: "4",
+	}
+
+	event.Hosts = append(event.Hosts, struct {
+		Hostid string `json:"hostid"`
+		Name   string `json:"name"`
+	}{Name: host})
+
+	return event
+}
+
+func TestTriggersAvailability(t *testing.T) {
+	test := assert.New(t)
+
+	const (
+		from = 1000
+		till = 2000
+	)
+
+	ongoing := zabbix.Trigger{ID: "4", Description: "trigger 4", Priority: "2"}
+	ongoing.Hosts = append(ongoing.Hosts, struct {
+		Hostid string `json:"hostid"`
+		Name   string `json:"name"`
+	}{Name: "web1"})
+
+	triggers := getTriggersAvailability(
+		[]zabbix.Event{
+			// the problem of trigger 1 began before the window
+			newReportEvent("1", "db1", zabbix.EventValueOK, 1100),
+			newReportEvent("2", "db1", zabbix.EventValueProblem, 1150),
+			newReportEvent("1", "db1", zabbix.EventValueProblem, 1200),
+			newReportEvent("2", "db1", zabbix.EventValueProblem, 1250),
+			newReportEvent("1", "db1", zabbix.EventValueOK, 1400),
+			newReportEvent("2", "db1", zabbix.EventValueOK, 1350),
+			// the problem of trigger 3 lasts after the window
+			newReportEvent("3", "web1", zabbix.EventValueProblem, 1900),
+		},
+		[]zabbix.Trigger{ongoing},
+		nil,
+		from, till,
+	)
+
+	if !test.Len(triggers, 4) {
+		return
+	}
+
+	test.Equal("1", triggers[0].id)
+	test.Equal(2, triggers[0].problems)
+	test.Equal([]problemInterval{
+		{from: 1000, till: 1100, resolved: true},
+		{from: 1200, till: 1400, resolved: true},
+	}, triggers[0].intervals)
+
+	test.Equal(2, triggers[1].problems)
+	test.Equal([]problemInterval{
+		{from: 1150, till: 1350, resolved: true},
+	}, triggers[1].intervals)
+
+	test.Equal([]problemInterval{{from: 1900, till: 2000}}, triggers[2].intervals)
+
+	test.Equal("4", triggers[3].id)
+	test.Equal("web1", triggers[3].host)
+	test.Equal([]problemInterval{{from: 1000, till: 2000}}, triggers[3].intervals)
+
+	rows := getTriggersAvailabilityRows(triggers, from, till)
+	test.Equal("4", rows[0].TriggerID)
+	test.Equal(0.0, rows[0].Availability)
+	test.Equal(int64(0), rows[0].MTTR)
+
+	test.Equal("1", rows[1].TriggerID)
+	test.Equal(int64(300), rows[1].ProblemTime)
+	test.Equal(int64(150), rows[1].MTTR)
+	test.Equal(70.0, rows[1].Availability)
+
+	rows = getHostsAvailabilityRows(triggers, from, till)
+	if test.Len(rows, 2) {
+		test.Equal("web1", rows[0].Host)
+		test.Equal(int64(1000), rows[0].ProblemTime)
+
+		// intervals of triggers 1 and 2 overlap
+		test.Equal("db1", rows[1].Host)
+		test.Equal(4, rows[1].Problems)
+		test.Equal(int64(100+250), rows[1].ProblemTime)
+		test.Equal(int64((100+200+200)/3), rows[1].MTTR)
+	}
+}
+
+func TestTriggersAvailability_Initial(t *testing.T) {
+	test := assert.New(t)
+
+	// trigger 5 was in a problem state at the start of the window and
+	// generated another problem
+	triggers := getTriggersAvailability(
+		[]zabbix.Event{
+			newReportEvent("5", "db1", zabbix.EventValueProblem, 1200),
+			newReportEvent("5", "db1", zabbix.EventValueOK, 1500),
+		},
+		nil,
+		map[string]bool{"5": true},
+		1000, 2000,
+	)
+
+	if test.Len(triggers, 1) {
+		test.Equal(2, triggers[0].problems)
+		test.Equal([]problemInterval{
+			{from: 1000, till: 1500, resolved: true},
+		}, triggers[0].intervals)
+	}
+}
+
+func TestAvailabilityEvents_PastWindow(t *testing.T) {
+	test := assert.New(t)
+
+	const (
+		from = 1000
+		till = 2000
+	)
+
+	host := []map[string]interface{}{{"hostid": "1", "name": "db1"}}
+
+	newEvent := func(trigger, value, clock string) map[string]interface{} {
+		return map[string]interface{}{
+			"eventid":  trigger + clock,
+			"objectid": trigger,
+			"clock":    clock,
+			"value":    value,
+			"name":     "trigger " + trigger,
+			"severity": "4",
+			"hosts":    host,
+		}
+	}
+
+	newTrigger := func(trigger string) map[string]interface{} {
+		return map[string]interface{}{
+			"triggerid":   trigger,
+			"description": "trigger " + trigger,
+			"priority":    "4",
+			"value":       "0",
+			"hosts":       host,
+		}
+	}
+
+	// trigger 1 was in a problem state during the whole window and
+	// recovered after it, trigger 2 was in a problem state at the start of
+	// the window, trigger 3 was fine
+	last := map[string]interface{}{
+		"1": newEvent("1", zabbix.EventValueProblem, "500"),
+		"2": newEvent("2", zabbix.EventValueProblem, "800"),
+		"3": newEvent("3", zabbix.EventValueOK, "900"),
+	}
+
+	testserver := newTestServer(func(request testRequest) interface{} {
+		switch request.Method {
+		case "apiinfo.version":
+			return "6.0.0"
+
+		case "event.get":
+			if _, ok := request.Params["time_from"]; ok {
+				return []interface{}{
+					newEvent("2", zabbix.EventValueProblem, "1200"),
+					newEvent("2", zabbix.EventValueOK, "1300"),
+				}
+			}
+
+			test.Equal(float64(from-1), request.Params["time_till"])
+
+			return []interface{}{last[request.Params["objectids"].(string)]}
+
+		case "trigger.get":
+			if _, ok := request.Params["lastChangeSince"]; ok {
+				return map[string]interface{}{
+					"1": newTrigger("1"),
+					"3": newTrigger("3"),
+				}
+			}
+
+			return []interface{}{}
+		}
+
+		t.Errorf("unexpected method %s", request.Method)
+
+		return nil
+	})
+	defer testserver.Close()
+
+	client, err := zabbix.NewZabbix(context.Background(), zabbix.Options{
+		Address: testserver.URL,
+		Token:   "token",
+	})
+	if !test.NoError(err) {
+		return
+	}
+
+	result := &serverAvailability{Server: Server{Name: "prod", Client: client}}
+
+	err = getAvailabilityEvents(
+		context.Background(), result, zabbix.Params{}, from, till,
+	)
+	if !test.NoError(err) {
+		return
+	}
+
+	test.Equal(map[string]bool{"2": true}, result.initial)
+
+	rows := getTriggersAvailabilityRows(
+		getTriggersAvailability(
+			result.events, result.ongoing, result.initial, from, till,
+		),
+		from, till,
+	)
+
+	if test.Len(rows, 2) {
+		test.Equal("1", rows[0].TriggerID)
+		test.Equal(int64(till-from), rows[0].ProblemTime)
+		test.Equal(0.0, rows[0].Availability)
+
+		test.Equal("2", rows[1].TriggerID)
+		test.Equal(int64(300), rows[1].ProblemTime)
+	}
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/lasseoe/zabbixctl/zabbix"
 	"github.com/reconquest/karma-go"
 )
 
@@ -18,16 +25,104 @@ const (
 	ExtendedOutputAll
 )
 
+// serverTriggers are triggers retrieved from a single server.
+type serverTriggers struct {
+	Server
+
+	triggers []zabbix.Trigger
+	history  map[string]zabbix.ItemHistory
+
+	// expressions are trigger expressions referring to functions by their
+	// identifiers, retrieved for --expression
+	expressions map[string]string
+}
+
+// triggerOutput is a trigger in the machine-readable output.
+type triggerOutput struct {
+	Server string `json:"server,omitempty"`
+	zabbix.Trigger
+	LastValue *zabbix.ItemHistory `json:"lastValue,omitempty"`
+}
+
+// Host returns the name of the trigger host.
+func (output *triggerOutput) Host() string {
+	return output.GetHostName()
+}
+
+var triggerColumns = []column[*triggerOutput]{
+	{"server", func(output *triggerOutput) string { return output.Server }},
+	{"id", func(output *triggerOutput) string { return output.LastEvent.ID }},
+	{"triggerid", func(output *triggerOutput) string { return output.ID }},
+	{"time", func(output *triggerOutput) string { return output.DateTime() }},
+	{"age", func(output *triggerOutput) string { return output.Age() }},
+	{"severity", func(output *triggerOutput) string {
+		return output.Severity().String()
+	}},
+	{"status", func(output *triggerOutput) string {
+		return output.StatusProblem()
+	}},
+	{"ack", func(output *triggerOutput) string {
+		return output.StatusAcknowledge()
+	}},
+	{"host", func(output *triggerOutput) string { return output.Host() }},
+	{"description", func(output *triggerOutput) string {
+		return output.Description
+	}},
+	{"tags", func(output *triggerOutput) string { return output.TagsString() }},
+	{"groups", func(output *triggerOutput) string {
+		return output.GroupsString()
+	}},
+	{"value", func(output *triggerOutput) string {
+		if output.LastValue == nil {
+			return ""
+		}
+
+		return output.LastValue.History.String()
+	}},
+	{"valuetime", func(output *triggerOutput) string {
+		if output.LastValue == nil {
+			return ""
+		}
+
+		return output.LastValue.History.DateTime()
+	}},
+	{"item", func(output *triggerOutput) string {
+		if output.LastValue == nil {
+			return ""
+		}
+
+		return output.LastValue.Item.Format()
+	}},
+}
+
+// triggerRow is a trigger listed in the output along with its server.
+type triggerRow struct {
+	*serverTriggers
+
+	trigger zabbix.Trigger
+}
+
 func handleTriggers(
-	zabbix *Zabbix,
+	ctx context.Context,
+	servers []Server,
 	config *Config,
 	args map[string]interface{},
 ) error {
 	var (
-		acknowledge    = args["--acknowledge"].(bool)
-		words, pattern = parseSearchQuery(args["<pattern>"].([]string))
-		confirmation   = !args["--noconfirm"].(bool)
-		extended       = ExtendedOutput(args["--extended"].(int))
+		words, query = parseSearchQuery(args)
+		confirmation = !args["--noconfirm"].(bool)
+		edit         = args["--edit"].(bool)
+		extended     = ExtendedOutput(args["--extended"].(int))
+		allContexts  = args["--all-contexts"].(bool)
+		order        = args["--order"].(string)
+		deps         = args["--deps"].(bool)
+		functions    = args["--functions"].(bool)
+		expression   = args["--expression"].(bool)
+		summary      = args["--summary"].(bool)
+		outputs      = []*triggerOutput{}
+		listed       = map[*serverTriggers][]zabbix.Trigger{}
+		selected     = map[*serverTriggers][]zabbix.Trigger{}
+		matched      = []triggerRow{}
 
 		table = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
 	)
@@ -40,131 +135,471 @@ func handleTriggers(
 		)
 	}
 
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
 	params, err := parseParams(args)
 	if err != nil {
 		return err
 	}
 
-	var triggers []Trigger
+	acknowledge, act, err := parseAcknowledgeOptions(args)
+	if err != nil {
+		return err
+	}
 
-	err = withSpinner(
-		":: Requesting information about statuses of triggers",
-		func() error {
-			triggers, err = zabbix.GetTriggers(params)
-			return err
-		},
-	)
+	status, changeStatus, err := parseTriggersStatus(args)
 	if err != nil {
-		return karma.Format(
-			err,
-			"can't obtain zabbix triggers",
+		return err
+	}
+
+	if changeStatus && act {
+		return errors.New(
+			"--enable and --disable can't be combined with actions on events",
 		)
 	}
 
-	var history = map[string]ItemHistory{}
+	top, err := strconv.Atoi(args["--top"].(string))
+	if err != nil || top < 0 {
+		return fmt.Errorf(
+			"unexpected --top amount '%s', expected non-negative number",
+			args["--top"],
+		)
+	}
 
-	if extended != ExtendedOutputNone {
-		history, err = getTriggerItemsHistory(zabbix, triggers)
-		if err != nil {
-			return karma.Format(
-				err,
-				`can't obtain history for items of triggers`,
+	if summary {
+		if act || changeStatus {
+			return errors.New(
+				"--summary can't be combined with actions on triggers",
+			)
+		}
+
+		if output.template != nil || len(output.columns) > 0 ||
+			output.format == outputCSV {
+			return errors.New(
+				"--summary can't be combined with --format, --columns and " +
+					"the csv output",
+			)
+		}
+	}
+
+	if interactive, _ := args["--interactive"].(bool); interactive {
+		if output.custom() || act || changeStatus {
+			return errors.New(
+				"--interactive can't be combined with the machine-readable " +
+					"output or actions on triggers",
+			)
+		}
+
+		return handleTriage(ctx, servers, params, parseHostFilters(args), args)
+	}
+
+	if watch, _ := args["--watch"].(string); watch != "" {
+		interval, err := time.ParseDuration(watch)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf(
+				"unexpected --watch interval '%s', expected duration like 30s",
+				watch,
 			)
 		}
+
+		if output.custom() || act || changeStatus {
+			return errors.New(
+				"--watch can't be combined with the machine-readable output " +
+					"or actions on triggers",
+			)
+		}
+
+		return watchTriggers(
+			ctx, servers, params, parseHostFilters(args), interval, config, args,
+		)
+	}
+
+	// last values are shown in the value, valuetime and item columns
+	for _, name := range output.columns {
+		if (name == "value" || name == "valuetime" || name == "item") &&
+			extended == ExtendedOutputNone {
+			extended = ExtendedOutputAll
+		}
+	}
+
+	results, err := getServersTriggers(
+		ctx, servers, params, parseHostFilters(args),
+		extended != ExtendedOutputNone || functions || expression,
+	)
+	if err != nil {
+		return err
 	}
 
+	if expression && !output.custom() {
+		err = getServersTriggerExpressions(ctx, results)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows := getTriggerRows(results, order)
+
 	debugln("* showing triggers table")
-	if pattern != "" {
-		debugf("** searching %s", pattern)
+	if !query.empty() {
+		debugf("** searching %s excluding %s", query.pattern, query.excludes)
 	}
 
-	identifiers := []string{}
-	for _, trigger := range triggers {
-		if pattern != "" && !matchPattern(pattern, trigger.String()) {
+	identifiers := map[*serverTriggers][]string{}
+	for _, row := range rows {
+		trigger := row.trigger
+
+		if !query.match(trigger.String()) {
 			continue
 		}
 
-		fmt.Fprintf(
-			table,
-			"%s\t%s\t%s\t%s\t%s\t%s\t%s",
-			trigger.LastEvent.ID, trigger.DateTime(),
-			trigger.Severity(),
-			trigger.StatusProblem(),
-			trigger.StatusAcknowledge(),
-			trigger.GetHostName(),
-			trigger.Description,
+		last := row.lastValue()
+
+		identifiers[row.serverTriggers] = append(
+			identifiers[row.serverTriggers],
+			trigger.LastEvent.ID,
 		)
 
-		if len(trigger.Functions) > 0 {
-			if last, ok := history[trigger.Functions[0].ItemID]; ok {
-				if extended >= ExtendedOutputValue {
-					fmt.Fprintf(table, "\t%s", last.History.String())
-				}
+		if changeStatus {
+			selected[row.serverTriggers] = append(
+				selected[row.serverTriggers], trigger,
+			)
+		}
 
-				if extended >= ExtendedOutputDate {
-					fmt.Fprintf(table, "\t%s", last.History.DateTime())
-				}
+		if summary {
+			matched = append(matched, row)
+			continue
+		}
 
-				if extended >= ExtendedOutputAll {
-					fmt.Fprintf(table, "\t%s", last.Item.Format())
-				}
+		if output.custom() {
+			entry := &triggerOutput{Trigger: trigger, LastValue: last}
+			if allContexts {
+				entry.Server = row.Name
 			}
+
+			outputs = append(outputs, entry)
+
+			continue
+		}
+
+		if deps {
+			listed[row.serverTriggers] = append(listed[row.serverTriggers], trigger)
+			continue
+		}
+
+		writeTriggerRow(table, row, extended, allContexts)
+
+		if functions {
+			writeTriggerFunctions(table, row, allContexts)
+		}
+
+		if expression {
+			writeTriggerExpression(table, row, allContexts)
 		}
+	}
 
-		fmt.Fprint(table, "\n")
+	if summary {
+		result := newTriggersSummary(matched, top, allContexts)
+		if output.custom() {
+			return printObjects(output.format, result)
+		}
 
-		identifiers = append(identifiers, trigger.LastEvent.ID)
+		return result.write(os.Stdout)
 	}
 
-	err = table.Flush()
+	if deps && !output.custom() {
+		err = writeTriggersDependencies(ctx, table, results, listed, extended, allContexts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if output.custom() {
+		err = printRows(output, triggerColumns, outputs)
+	} else {
+		err = table.Flush()
+	}
 	if err != nil {
 		return err
 	}
 
-	if !acknowledge || len(identifiers) == 0 {
+	if changeStatus {
+		changed := []serverTriggersStatus{}
+		for _, result := range results {
+			if len(selected[result]) > 0 {
+				changed = append(changed, serverTriggersStatus{
+					Server:   result.Server,
+					triggers: selected[result],
+				})
+			}
+		}
+
+		return updateTriggersStatus(
+			ctx, changed, status, confirmation, allContexts,
+		)
+	}
+
+	if !act {
 		return nil
 	}
 
-	if confirmation {
-		if !confirmAcknowledge() {
-			return nil
+	events := []serverEvents{}
+	for _, result := range results {
+		if len(identifiers[result]) > 0 {
+			events = append(events, serverEvents{
+				Server:      result.Server,
+				identifiers: identifiers[result],
+			})
 		}
 	}
 
-	err = withSpinner(
-		":: Acknowledging specified triggers",
+	return updateEvents(ctx, events, acknowledge, edit, confirmation)
+}
+
+// getServersTriggers retrieves triggers (and history of their items when
+// history is set) from every server in parallel.
+func getServersTriggers(
+	ctx context.Context,
+	servers []Server,
+	params zabbix.Params,
+	filters hostFilters,
+	history bool,
+) ([]*serverTriggers, error) {
+	results := make([]*serverTriggers, len(servers))
+	for index, server := range servers {
+		results[index] = &serverTriggers{Server: server}
+	}
+
+	var errs []error
+
+	err := withSpinner(
+		":: Requesting information about statuses of triggers",
 		func() error {
-			return zabbix.Acknowledge(identifiers)
+			errs = fanOut(len(results), func(index int) error {
+				params, err := filters.resolve(
+					ctx, results[index].Client, params,
+				)
+				if err != nil {
+					return err
+				}
+
+				results[index].triggers, err = results[index].Client.GetTriggers(
+					ctx, params,
+				)
+
+				return err
+			})
+
+			return nil
 		},
 	)
+	if err == nil {
+		results, err = skipFailed(servers, results, errs, "can't obtain zabbix triggers")
+	}
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"can't obtain zabbix triggers",
+		)
+	}
+
+	if !history {
+		return results, nil
+	}
+
+	servers = []Server{}
+	for _, result := range results {
+		servers = append(servers, result.Server)
+	}
+
+	err = withSpinner(
+		":: Requesting history for items of triggers",
+		func() error {
+			errs = fanOut(len(results), func(index int) error {
+				var err error
+
+				results[index].history, err = getTriggerItemsHistory(
+					ctx, results[index].Client, results[index].triggers,
+				)
+
+				return err
+			})
 
+			return nil
+		},
+	)
+	if err == nil {
+		// triggers are still shown when history can't be obtained
+		_, err = skipFailed(servers, results, errs, "can't obtain history for items of triggers")
+	}
 	if err != nil {
-		return karma.Format(
+		return nil, karma.Format(
 			err,
-			"can't acknowledge triggers %s",
-			identifiers,
+			`can't obtain history for items of triggers`,
 		)
 	}
 
-	fmt.Fprintln(os.Stderr, ":: Acknowledged")
+	return results, nil
+}
+
+// writeTriggersDependencies writes trees of dependencies of the listed
+// triggers of every server.
+func writeTriggersDependencies(
+	ctx context.Context,
+	table io.Writer,
+	results []*serverTriggers,
+	listed map[*serverTriggers][]zabbix.Trigger,
+	extended ExtendedOutput,
+	allContexts bool,
+) error {
+	for _, result := range results {
+		if len(listed[result]) == 0 {
+			continue
+		}
+
+		var dependencies *triggerDependencies
+
+		err := withSpinner(
+			":: Requesting dependencies of triggers",
+			func() error {
+				var err error
+
+				dependencies, err = getTriggerDependencies(
+					ctx, result.Client, listed[result],
+				)
+
+				return err
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		dependencies.write(table, result, listed[result], extended, allContexts)
+	}
 
 	return nil
 }
 
-func getTriggerItemsHistory(
-	zabbix *Zabbix,
-	triggers []Trigger,
-) (map[string]ItemHistory, error) {
-	history := map[string]ItemHistory{}
+// getTriggerRows returns triggers of every server, triggers of several servers
+// are sorted by the last change in the given order.
+func getTriggerRows(results []*serverTriggers, order string) []triggerRow {
+	rows := []triggerRow{}
+	for _, result := range results {
+		for _, trigger := range result.triggers {
+			rows = append(rows, triggerRow{
+				serverTriggers: result,
+				trigger:        trigger,
+			})
+		}
+	}
+
+	if len(results) > 1 {
+		sortTriggersByLastChange(rows, order)
+	}
+
+	return rows
+}
+
+// lastValue returns the last value of the item from the first function of the
+// trigger expression, nil when it's not retrieved.
+func (row triggerRow) lastValue() *zabbix.ItemHistory {
+	if len(row.trigger.Functions) == 0 {
+		return nil
+	}
+
+	history, ok := row.history[row.trigger.Functions[0].ItemID]
+	if !ok {
+		return nil
+	}
+
+	return &history
+}
+
+// writeTriggerRow writes the trigger as a line of the table.
+func writeTriggerRow(
+	table io.Writer,
+	row triggerRow,
+	extended ExtendedOutput,
+	allContexts bool,
+) {
+	trigger := row.trigger
+
+	if allContexts {
+		fmt.Fprintf(table, "%s\t", row.Name)
+	}
+
+	fmt.Fprintf(
+		table,
+		"%s\t%s\t%s\t%s\t%s\t%s\t%s",
+		trigger.LastEvent.ID, trigger.DateTime(),
+		trigger.Severity(),
+		trigger.StatusProblem(),
+		trigger.StatusAcknowledge(),
+		trigger.GetHostName(),
+		trigger.Description,
+	)
+
+	if last := row.lastValue(); last != nil {
+		if extended >= ExtendedOutputValue {
+			fmt.Fprintf(table, "\t%s", last.History.String())
+		}
 
+		if extended >= ExtendedOutputDate {
+			fmt.Fprintf(table, "\t%s", last.History.DateTime())
+		}
+
+		if extended >= ExtendedOutputAll {
+			fmt.Fprintf(table, "\t%s", last.Item.Format())
+		}
+	}
+
+	fmt.Fprint(table, "\n")
+}
+
+func sortTriggersByLastChange(rows []triggerRow, order string) {
+	ascending := strings.EqualFold(order, "ASC")
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, _ := strconv.ParseInt(rows[i].trigger.LastChange, 10, 64)
+		b, _ := strconv.ParseInt(rows[j].trigger.LastChange, 10, 64)
+
+		if ascending {
+			return a < b
+		}
+
+		return a > b
+	})
+}
+
+func getTriggerItemsHistory(
+	ctx context.Context,
+	client *zabbix.Zabbix,
+	triggers []zabbix.Trigger,
+) (map[string]zabbix.ItemHistory, error) {
+	history := map[string]zabbix.ItemHistory{}
+
+	// items of every function are retrieved for --functions and
+	// --expression, items can repeat within the expression
 	itemIDs := []string{}
+	seen := map[string]bool{}
 	for _, trigger := range triggers {
-		if len(trigger.Functions) > 0 {
-			itemIDs = append(itemIDs, trigger.Functions[0].ItemID)
+		for _, function := range trigger.Functions {
+			if !seen[function.ItemID] {
+				seen[function.ItemID] = true
+				itemIDs = append(itemIDs, function.ItemID)
+			}
 		}
 	}
 
-	items, err := zabbix.GetItems(Params{
+	// item.get returns every item when itemids are empty
+	if len(itemIDs) == 0 {
+		return history, nil
+	}
+
+	items, err := client.GetItems(ctx, zabbix.Params{
 		"itemids": itemIDs,
 	})
 	if err != nil {
@@ -174,48 +609,39 @@ func getTriggerItemsHistory(
 		)
 	}
 
-	err = withSpinner(
-		":: Requesting history for items of triggers",
-		func() error {
-			for _, item := range items {
-				var lastValues []History
-				lastValues, err = zabbix.GetHistory(Params{
-					"history": item.ValueType,
-					"itemids": item.ID,
-					"limit":   1,
-				})
-				if err != nil {
-					return karma.Format(
-						err,
-						`can't obtain history (type '%s') for item '%s'`,
-						item.ValueType,
-						item.ID,
-					)
-				}
-
-				if len(lastValues) == 0 {
-					continue
-				}
+	lastValues, err := client.GetLastHistory(ctx, items)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			`can't obtain history for items of triggers`,
+		)
+	}
 
-				history[item.ID] = ItemHistory{
-					Item:    item,
-					History: lastValues[0],
-				}
-			}
+	for _, item := range items {
+		value, ok := lastValues[item.ID]
+		if !ok {
+			continue
+		}
 
-			return nil
-		},
-	)
+		history[item.ID] = zabbix.ItemHistory{
+			Item:    item,
+			History: value,
+		}
+	}
 
-	return history, err
+	return history, nil
 }
 
-func parseParams(args map[string]interface{}) (Params, error) {
+func parseParams(args map[string]interface{}) (zabbix.Params, error) {
 	var (
-		severity    = args["--severity"].(int)
+		severity    = args["-x"].(int)
 		onlyNotAck  = args["--only-nack"].(bool)
 		maintenance = args["--maintenance"].(bool)
+		enable, _   = args["--enable"].(bool)
+		dependent   = args["--with-dependent"].(bool)
+		deps        = args["--deps"].(bool)
 		problem     = args["--problem"].(bool)
+		summary, _  = args["--summary"].(bool)
 		recent      = args["--recent"].(bool)
 		since, _    = args["--since"].(string)
 		until, _    = args["--until"].(string)
@@ -224,7 +650,11 @@ func parseParams(args map[string]interface{}) (Params, error) {
 		limit       = args["--limit"].(string)
 	)
 
-	params := Params{
+	if named, _ := args["--severity"].(string); named != "" && severity > 0 {
+		return nil, errors.New("-x and --severity are mutually exclusive")
+	}
+
+	params := zabbix.Params{
 		"sortfield":    sort,
 		"sortorder":    order,
 		"min_severity": severity,
@@ -243,26 +673,48 @@ func parseParams(args map[string]interface{}) (Params, error) {
 		params["only_true"] = "1"
 	}
 
-	if problem {
-		params["filter"] = Params{"value": "1"}
+	filter := zabbix.Params{}
+
+	// the summary counts only problems
+	if problem || summary {
+		filter["value"] = "1"
+	}
+
+	// only monitored triggers are listed by default, disabled triggers are
+	// listed for enabling them
+	if enable {
+		params["monitored"] = nil
+		filter["status"] = zabbix.TriggerStatusDisabled
+	}
+
+	if len(filter) > 0 {
+		params["filter"] = filter
+	}
+
+	// dependent triggers are skipped while triggers they depend on are in a
+	// problem state
+	if dependent || deps {
+		params["skipDependent"] = false
+	}
+
+	if deps {
+		params["selectDependencies"] = dependenciesFields
+	}
+
+	err := setTagFilters(params, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if since == "" {
+		since = defaultSince
 	}
 
-	var err error
 	if until != "" {
 		params["lastChangeTill"], err = parseDateTime(until)
-	} else if since != "" {
+	} else {
 		params["lastChangeSince"], err = parseDateTime(since)
 	}
 
 	return params, err
 }
-
-func confirmAcknowledge() bool {
-	var value string
-	fmt.Fprintf(os.Stderr, "\n:: Proceed with acknowledge? [Y/n]: ")
-	_, err := fmt.Scanln(&value)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
-	return value == "" || value == "Y" || value == "y"
-}
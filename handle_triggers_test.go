@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	ID     int64                  `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// newTestServer returns a server answering single and batch requests with
+// results of the given function.
+func newTestServer(result func(request testRequest) interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var body json.RawMessage
+			err := json.NewDecoder(r.Body).Decode(&body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			var requests []testRequest
+			if json.Unmarshal(body, &requests) != nil {
+				var request testRequest
+				json.Unmarshal(body, &request)
+
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"jsonrpc": "2.0",
+					"result":  result(request),
+					"id":      request.ID,
+				})
+
+				return
+			}
+
+			responses := []interface{}{}
+			for index := len(requests) - 1; index >= 0; index-- {
+				responses = append(responses, map[string]interface{}{
+					"jsonrpc": "2.0",
+					"result":  result(requests[index]),
+					"id":      requests[index].ID,
+				})
+			}
+
+			json.NewEncoder(w).Encode(responses)
+		},
+	))
+}
+
+// newTriggersTestServer returns a server with items 10 (float), 20 (text)
+// and 30 (no history), the last value of an item is its key, and triggers 1
+// and 2 (disabled).
+func newTriggersTestServer(t *testing.T) *httptest.Server {
+	items := []map[string]interface{}{
+		{"itemid": "10", "value_type": "0", "key_": "system.cpu.load"},
+		{"itemid": "20", "value_type": "4", "key_": "agent.version"},
+		{"itemid": "30", "value_type": "3", "key_": "vfs.fs.size"},
+	}
+
+	result := func(request testRequest) interface{} {
+		switch request.Method {
+		case "apiinfo.version":
+			return "6.0.0"
+
+		case "item.get":
+			return items
+
+		case "trigger.get":
+			// trigger 2 is disabled
+			triggers := map[string]interface{}{
+				"1": map[string]interface{}{
+					"triggerid": "1", "expression": "{13}>90",
+				},
+			}
+			if _, ok := request.Params["monitored"]; !ok {
+				triggers["2"] = map[string]interface{}{
+					"triggerid": "2", "expression": "{14}=0",
+				}
+			}
+
+			return triggers
+
+		case "history.get":
+			for _, item := range items {
+				if item["itemid"] != request.Params["itemids"] ||
+					item["itemid"] == "30" {
+					continue
+				}
+
+				return []interface{}{map[string]interface{}{
+					"itemid": item["itemid"],
+					"value":  item["key_"],
+					"clock":  "1700000000",
+				}}
+			}
+
+			return []interface{}{}
+		}
+
+		t.Errorf("unexpected method %s", request.Method)
+
+		return nil
+	}
+
+	return newTestServer(result)
+}
+
+func TestGetTriggerItemsHistory(t *testing.T) {
+	test := assert.New(t)
+
+	testserver := newTriggersTestServer(t)
+	defer testserver.Close()
+
+	client, err := zabbix.NewZabbix(context.Background(), zabbix.Options{
+		Address: testserver.URL,
+		Token:   "token",
+	})
+	if !test.NoError(err) {
+		return
+	}
+
+	triggers := []zabbix.Trigger{
+		{ID: "1", Functions: []zabbix.Function{{ItemID: "20"}}},
+		{ID: "2", Functions: []zabbix.Function{{ItemID: "10"}}},
+		{ID: "3", Functions: []zabbix.Function{{ItemID: "30"}}},
+		{ID: "4"},
+	}
+
+	history, err := getTriggerItemsHistory(
+		context.Background(), client, triggers,
+	)
+	test.NoError(err)
+	test.Len(history, 2)
+
+	test.Equal("agent.version", history["20"].History.Value)
+	test.Equal("20", history["20"].Item.ID)
+	test.Equal("system.cpu.load", history["10"].History.Value)
+	test.Equal("10", history["10"].Item.ID)
+
+	_, ok := history["30"]
+	test.False(ok)
+
+	history, err = getTriggerItemsHistory(
+		context.Background(), client, triggers[3:],
+	)
+	test.NoError(err)
+	test.Empty(history)
+}
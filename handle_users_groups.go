@@ -1,37 +1,54 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/lasseoe/zabbixctl/zabbix"
 	"github.com/reconquest/karma-go"
 )
 
+var usersGroupColumns = []column[zabbix.UserGroup]{
+	{"id", func(group zabbix.UserGroup) string { return group.ID }},
+	{"status", func(group zabbix.UserGroup) string { return group.GetStatus() }},
+	{"name", func(group zabbix.UserGroup) string { return group.Name }},
+	{"users", func(group zabbix.UserGroup) string {
+		return strings.Join(getUsersAliases(group), " ")
+	}},
+}
+
 func handleUsersGroups(
-	zabbix *Zabbix,
+	ctx context.Context,
+	client *zabbix.Zabbix,
 	config *Config,
 	args map[string]interface{},
 ) error {
 	var (
-		groups, pattern = parseSearchQuery(args["<pattern>"].([]string))
+		groups, query = parseSearchQuery(args)
 
 		addUser, _    = args["--add"].(string)
 		removeUser, _ = args["--remove"].(string)
 		confirmation  = !args["--noconfirm"].(bool)
 
-		table = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+		matched = []zabbix.UserGroup{}
+		table   = tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
 	)
 
-	var usersgroups []UserGroup
-	var err error
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		return err
+	}
+
+	var usersgroups []zabbix.UserGroup
 
 	err = withSpinner(
 		":: Requesting information about users groups",
 		func() error {
-			usersgroups, err = getUsersGroups(zabbix, groups)
+			usersgroups, err = getUsersGroups(ctx, client, groups)
 			return err
 		},
 	)
@@ -45,28 +62,34 @@ func handleUsersGroups(
 
 	found := false
 	for _, group := range usersgroups {
-		names := []string{}
-		for _, user := range group.Users {
-			names = append(names, user.Alias)
-		}
-
 		line := fmt.Sprintf(
 			"%s\t%s\t%s",
 			group.GetStatus(), group.Name,
-			strings.Join(names, " "),
+			strings.Join(getUsersAliases(group), " "),
 		)
 
-		if pattern != "" && !matchPattern(pattern, line) {
+		if !query.match(line) {
 			continue
 		}
 
-		fmt.Fprintln(table, line)
+		matched = append(matched, group)
 		found = true
+
+		if !output.custom() {
+			fmt.Fprintln(table, line)
+		}
 	}
 
-	err = table.Flush()
-	if err != nil {
-		debugf("Error: %+v", err)
+	if output.custom() {
+		err = printRows(output, usersGroupColumns, matched)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = table.Flush()
+		if err != nil {
+			debugf("Error: %+v", err)
+		}
 	}
 
 	if !found || (addUser == "" && removeUser == "") {
@@ -81,7 +104,7 @@ func handleUsersGroups(
 			}
 		}
 
-		user, err := getUser(zabbix, addUser)
+		user, err := getUser(ctx, client, addUser)
 		if err != nil {
 			return karma.Format(
 				err,
@@ -92,7 +115,7 @@ func handleUsersGroups(
 		err = withSpinner(
 			":: Requesting for adding user to specified groups",
 			func() error {
-				return zabbix.AddUserToGroups(usersgroups, user)
+				return client.AddUserToGroups(ctx, usersgroups, user)
 			},
 		)
 
@@ -111,7 +134,7 @@ func handleUsersGroups(
 			}
 		}
 
-		user, err := getUser(zabbix, removeUser)
+		user, err := getUser(ctx, client, removeUser)
 		if err != nil {
 			return karma.Format(
 				err,
@@ -122,7 +145,7 @@ func handleUsersGroups(
 		err = withSpinner(
 			":: Requesting for removing user from specified groups",
 			func() error {
-				return zabbix.RemoveUserFromGroups(usersgroups, user)
+				return client.RemoveUserFromGroups(ctx, usersgroups, user)
 			},
 		)
 
@@ -138,42 +161,51 @@ func handleUsersGroups(
 	return nil
 }
 
-func getUser(zabbix *Zabbix, username string) (User, error) {
-	users, err := zabbix.GetUsers(Params{
-		"search": Params{
+func getUsersAliases(group zabbix.UserGroup) []string {
+	aliases := []string{}
+	for _, user := range group.Users {
+		aliases = append(aliases, user.Alias)
+	}
+
+	return aliases
+}
+
+func getUser(ctx context.Context, client *zabbix.Zabbix, username string) (zabbix.User, error) {
+	users, err := client.GetUsers(ctx, zabbix.Params{
+		"search": zabbix.Params{
 			"alias": username,
 		},
 	})
 	if err != nil {
-		return User{}, karma.Format(
+		return zabbix.User{}, karma.Format(
 			err, "can't obtain user with specified name",
 		)
 	}
 
 	if len(users) == 0 {
-		return User{}, errors.New("user with specified name not found")
+		return zabbix.User{}, errors.New("user with specified name not found")
 	}
 
 	return users[0], nil
 }
 
-func getUsersGroups(zabbix *Zabbix, groups []string) ([]UserGroup, error) {
-	var params Params
+func getUsersGroups(ctx context.Context, client *zabbix.Zabbix, groups []string) ([]zabbix.UserGroup, error) {
+	var params zabbix.Params
 	if len(groups) == 0 {
-		params = Params{
+		params = zabbix.Params{
 			"selectUsers": "1",
 		}
 	} else {
-		params = Params{
+		params = zabbix.Params{
 			"selectUsers": "1",
-			"search": Params{
+			"search": zabbix.Params{
 				"name": groups,
 			},
 			"searchWildcardsEnabled": "1",
 		}
 	}
 
-	usersgroups, err := zabbix.GetUsersGroups(params)
+	usersgroups, err := client.GetUsersGroups(ctx, params)
 	if err != nil {
 		return nil, karma.Format(
 			err,
@@ -183,7 +215,7 @@ func getUsersGroups(zabbix *Zabbix, groups []string) ([]UserGroup, error) {
 
 	var (
 		usersIdentifiers     = []string{}
-		usersIdentifiersHash = map[string]User{}
+		usersIdentifiersHash = map[string]zabbix.User{}
 	)
 
 	for _, usersgroup := range usersgroups {
@@ -192,8 +224,8 @@ func getUsersGroups(zabbix *Zabbix, groups []string) ([]UserGroup, error) {
 		}
 	}
 
-	users, err := zabbix.GetUsers(
-		Params{
+	users, err := client.GetUsers(ctx,
+		zabbix.Params{
 			"userids": usersIdentifiers,
 		},
 	)
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	hookEventProblem  = "problem"
+	hookEventResolved = "resolved"
+
+	hookTimeout = time.Second * 30
+)
+
+// hooksClient is the part of the Zabbix client used by hooks for checking
+// triggers of notified problems which aren't listed.
+type hooksClient interface {
+	GetTriggers(ctx context.Context, params zabbix.Params) ([]zabbix.Trigger, error)
+}
+
+// hookEvent is a new or resolved problem passed to hooks as JSON.
+type hookEvent struct {
+	Event string `json:"event"`
+	triggerOutput
+}
+
+// watchHooks runs the command and posts to the URL of the [hooks] section for
+// new and resolved problems of --watch. Notified problems are kept in the
+// state file, so problems aren't notified again after restart.
+type watchHooks struct {
+	command string
+	url     string
+	state   string
+	client  *http.Client
+
+	// problems are notified problems by their server, trigger and event
+	problems map[string]*triggerOutput
+
+	// seeded are servers which problems are recorded, it's nil unless the
+	// state file is created by this run: problems which already exist are
+	// recorded by the first refresh of their server without running hooks,
+	// so the first run doesn't notify every problem of busy servers
+	seeded map[string]bool
+}
+
+// newWatchHooks returns hooks of the configuration with problems from the
+// state file, nil is returned when no hook is configured.
+func newWatchHooks(config *Config) (*watchHooks, error) {
+	if config.Hooks.Command == "" && config.Hooks.URL == "" {
+		return nil, nil
+	}
+
+	hooks := &watchHooks{
+		command:  config.Hooks.Command,
+		url:      config.Hooks.URL,
+		state:    config.Hooks.State,
+		client:   &http.Client{Timeout: hookTimeout},
+		problems: map[string]*triggerOutput{},
+	}
+
+	if hooks.state == "" {
+		cache, err := os.UserCacheDir()
+		if err != nil {
+			return nil, karma.Format(
+				err,
+				"can't find the directory for the hooks state, "+
+					"use the 'state' setting in the [hooks] section",
+			)
+		}
+
+		hooks.state = filepath.Join(cache, "zabbixctl.hooks")
+	}
+
+	contents, err := os.ReadFile(hooks.state)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		hooks.seeded = map[string]bool{}
+		return hooks, nil
+	case err != nil:
+		return nil, karma.Format(err, "can't read hooks state %s", hooks.state)
+	}
+
+	err = json.Unmarshal(contents, &hooks.problems)
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"can't decode hooks state %s", hooks.state,
+		)
+	}
+
+	return hooks, nil
+}
+
+// notify runs hooks for problems of rows which weren't notified yet and for
+// notified problems of refreshed servers which triggers are in the OK state.
+// Triggers which aren't listed, e.g. filtered out by the pattern or --since,
+// are checked using the client of their server. Problems are notified again
+// by the next call when hooks fail. Problems of servers which aren't seeded
+// yet are recorded without running hooks.
+func (hooks *watchHooks) notify(
+	ctx context.Context,
+	rows []triggerRow,
+	refreshed map[string]hooksClient,
+) error {
+	var (
+		// listed are triggers by their server and identifier
+		listed  = map[string]*triggerOutput{}
+		failed  = map[string]bool{}
+		errs    []string
+		changed bool
+	)
+
+	for _, row := range rows {
+		key := problemKey(row)
+
+		listed[row.Name+"/"+row.trigger.ID] = &triggerOutput{
+			Server:    row.Name,
+			Trigger:   row.trigger,
+			LastValue: row.lastValue(),
+		}
+
+		if row.trigger.Value != "1" {
+			continue
+		}
+
+		if _, ok := hooks.problems[key]; ok {
+			continue
+		}
+
+		output := &triggerOutput{
+			Server:    row.Name,
+			Trigger:   row.trigger,
+			LastValue: row.lastValue(),
+		}
+
+		if hooks.seeded != nil && !hooks.seeded[row.Name] {
+			hooks.problems[key] = output
+			continue
+		}
+
+		err := hooks.run(ctx, hookEvent{Event: hookEventProblem, triggerOutput: *output})
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		hooks.problems[key] = output
+		changed = true
+	}
+
+	// the state is written after seeding, so problems which appear before
+	// the next run are notified by it
+	for server := range refreshed {
+		if hooks.seeded != nil && !hooks.seeded[server] {
+			hooks.seeded[server] = true
+			changed = true
+		}
+	}
+
+	unlisted := map[string][]string{}
+	for _, output := range hooks.problems {
+		_, ok := listed[output.Server+"/"+output.ID]
+		if ok || refreshed[output.Server] == nil {
+			continue
+		}
+
+		unlisted[output.Server] = append(unlisted[output.Server], output.ID)
+	}
+
+	for server, identifiers := range unlisted {
+		triggers, err := refreshed[server].GetTriggers(ctx, zabbix.Params{
+			"triggerids":    identifiers,
+			"monitored":     nil,
+			"skipDependent": nil,
+		})
+		if err != nil {
+			// problems of the server are checked again by the next call
+			errs = append(errs, fmt.Sprintf(
+				"can't check triggers of %s: %s", server, err,
+			))
+			failed[server] = true
+
+			continue
+		}
+
+		for _, trigger := range triggers {
+			listed[server+"/"+trigger.ID] = &triggerOutput{
+				Server:  server,
+				Trigger: trigger,
+			}
+		}
+	}
+
+	keys := []string{}
+	for key := range hooks.problems {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		notified := hooks.problems[key]
+		if refreshed[notified.Server] == nil || failed[notified.Server] {
+			continue
+		}
+
+		output, ok := listed[notified.Server+"/"+notified.ID]
+		switch {
+		case !ok:
+			// the trigger is deleted, its problem can't be resolved anymore
+			delete(hooks.problems, key)
+			changed = true
+
+			continue
+		case output.Value != "0":
+			continue
+		}
+
+		err := hooks.run(ctx, hookEvent{Event: hookEventResolved, triggerOutput: *output})
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		delete(hooks.problems, key)
+		changed = true
+	}
+
+	if changed {
+		err := hooks.save()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// run passes the event to the command on stdin and posts it to the URL.
+func (hooks *watchHooks) run(ctx context.Context, event hookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	if hooks.command != "" {
+		var stderr bytes.Buffer
+
+		command := exec.CommandContext(ctx, "sh", "-c", hooks.command)
+		command.Stdin = bytes.NewReader(body)
+		command.Stderr = &stderr
+
+		err := command.Run()
+		if err != nil {
+			return fmt.Errorf(
+				"command for %s event %s: %s %s",
+				event.Event, event.LastEvent.ID, err,
+				strings.TrimSpace(stderr.String()),
+			)
+		}
+	}
+
+	if hooks.url != "" {
+		request, err := http.NewRequestWithContext(
+			ctx, http.MethodPost, hooks.url, bytes.NewReader(body),
+		)
+		if err != nil {
+			return err
+		}
+
+		request.Header.Set("Content-Type", "application/json")
+
+		response, err := hooks.client.Do(request)
+		if err != nil {
+			return fmt.Errorf(
+				"url for %s event %s: %s",
+				event.Event, event.LastEvent.ID, err,
+			)
+		}
+
+		io.Copy(io.Discard, response.Body)
+		response.Body.Close()
+
+		if response.StatusCode/100 != 2 {
+			return fmt.Errorf(
+				"url for %s event %s: unexpected status %s",
+				event.Event, event.LastEvent.ID, response.Status,
+			)
+		}
+	}
+
+	return nil
+}
+
+// save writes notified problems to the state file.
+func (hooks *watchHooks) save() error {
+	contents, err := json.Marshal(hooks.problems)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(hooks.state), 0700)
+	if err != nil {
+		return karma.Format(err, "can't write hooks state %s", hooks.state)
+	}
+
+	// the state is replaced at once, so it's never written partially
+	temporary := hooks.state + ".tmp"
+
+	err = os.WriteFile(temporary, contents, 0600)
+	if err == nil {
+		err = os.Rename(temporary, hooks.state)
+	}
+	if err != nil {
+		return karma.Format(err, "can't write hooks state %s", hooks.state)
+	}
+
+	return nil
+}
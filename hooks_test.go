@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+// hooksTestClient returns triggers requested by their identifiers.
+type hooksTestClient struct {
+	triggers map[string]zabbix.Trigger
+	err      error
+	requests int
+}
+
+func (client *hooksTestClient) GetTriggers(
+	ctx context.Context,
+	params zabbix.Params,
+) ([]zabbix.Trigger, error) {
+	client.requests++
+
+	if client.err != nil {
+		return nil, client.err
+	}
+
+	triggers := []zabbix.Trigger{}
+	for _, identifier := range params["triggerids"].([]string) {
+		if trigger, ok := client.triggers[identifier]; ok {
+			triggers = append(triggers, trigger)
+		}
+	}
+
+	return triggers, nil
+}
+
+func TestWatchHooksNotify(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		dir       = t.TempDir()
+		output    = filepath.Join(dir, "events")
+		server    = &serverTriggers{Server: Server{Name: "prod"}}
+		client    = &hooksTestClient{}
+		refreshed = map[string]hooksClient{"prod": client}
+		posted    = []hookEvent{}
+		status    = http.StatusOK
+	)
+
+	testserver := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			test.Equal("application/json", r.Header.Get("Content-Type"))
+
+			var event hookEvent
+			test.NoError(json.NewDecoder(r.Body).Decode(&event))
+
+			posted = append(posted, event)
+
+			w.WriteHeader(status)
+		},
+	))
+	defer testserver.Close()
+
+	config := &Config{}
+	config.Hooks.Command = "cat >> " + output + " && echo >> " + output
+	config.Hooks.URL = testserver.URL
+	config.Hooks.State = filepath.Join(dir, "state")
+
+	// problems are notified once the state exists
+	err := os.WriteFile(config.Hooks.State, []byte("{}"), 0600)
+	if !test.NoError(err) {
+		return
+	}
+
+	hooks, err := newWatchHooks(config)
+	if !test.NoError(err) {
+		return
+	}
+
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(server, "1", "10", "1", "4"),
+		newWatchRow(server, "2", "20", "0", "2"),
+	}, refreshed)
+	test.NoError(err)
+
+	if test.Len(posted, 1) {
+		test.Equal("problem", posted[0].Event)
+		test.Equal("prod", posted[0].Server)
+		test.Equal("1", posted[0].ID)
+		test.Equal("10", posted[0].LastEvent.ID)
+	}
+
+	contents, err := os.ReadFile(output)
+	test.NoError(err)
+	test.Contains(string(contents), `"event":"problem"`)
+	test.Contains(string(contents), `"triggerid":"1"`)
+
+	// problems from the state aren't notified again after restart
+	hooks, err = newWatchHooks(config)
+	if !test.NoError(err) {
+		return
+	}
+
+	test.Len(hooks.problems, 1)
+
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(server, "1", "10", "1", "4"),
+	}, refreshed)
+	test.NoError(err)
+	test.Len(posted, 1)
+
+	// problems of servers which weren't refreshed aren't resolved
+	err = hooks.notify(context.Background(), nil, map[string]hooksClient{})
+	test.NoError(err)
+	test.Len(posted, 1)
+	test.Zero(client.requests)
+
+	// problems which aren't listed, e.g. filtered out, are resolved only by
+	// the OK state of their triggers
+	client.triggers = map[string]zabbix.Trigger{
+		"1": newWatchRow(server, "1", "10", "1", "4").trigger,
+	}
+
+	err = hooks.notify(context.Background(), nil, refreshed)
+	test.NoError(err)
+	test.Len(posted, 1)
+	test.Equal(1, client.requests)
+
+	client.err = errors.New("session terminated")
+
+	err = hooks.notify(context.Background(), nil, refreshed)
+	if test.Error(err) {
+		test.Contains(err.Error(), "can't check triggers of prod")
+	}
+
+	test.Len(posted, 1)
+	test.Len(hooks.problems, 1)
+
+	client.err = nil
+	client.triggers["1"] = newWatchRow(server, "1", "11", "0", "4").trigger
+
+	// failed hooks are run again by the next refresh
+	status = http.StatusBadGateway
+
+	err = hooks.notify(context.Background(), nil, refreshed)
+	if test.Error(err) {
+		test.Contains(err.Error(), "502")
+	}
+
+	test.Len(posted, 2)
+	test.Len(hooks.problems, 1)
+
+	status = http.StatusOK
+
+	err = hooks.notify(context.Background(), nil, refreshed)
+	test.NoError(err)
+
+	if test.Len(posted, 3) {
+		test.Equal("resolved", posted[2].Event)
+		test.Equal("1", posted[2].ID)
+		test.Equal("0", posted[2].Value)
+	}
+
+	test.Empty(hooks.problems)
+
+	contents, err = os.ReadFile(output)
+	test.NoError(err)
+	test.Equal(3, strings.Count(string(contents), "\n"))
+
+	contents, err = os.ReadFile(config.Hooks.State)
+	test.NoError(err)
+	test.Equal("{}", string(contents))
+}
+
+func TestWatchHooksCommandError(t *testing.T) {
+	test := assert.New(t)
+
+	config := &Config{}
+	config.Hooks.Command = "echo broken >&2; exit 1"
+	config.Hooks.State = filepath.Join(t.TempDir(), "state")
+
+	err := os.WriteFile(config.Hooks.State, []byte("{}"), 0600)
+	if !test.NoError(err) {
+		return
+	}
+
+	hooks, err := newWatchHooks(config)
+	if !test.NoError(err) {
+		return
+	}
+
+	server := &serverTriggers{Server: Server{Name: "prod"}}
+
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(server, "1", "10", "1", "4"),
+	}, map[string]hooksClient{"prod": &hooksTestClient{}})
+	if test.Error(err) {
+		test.Contains(err.Error(), "broken")
+	}
+
+	test.Empty(hooks.problems)
+
+	contents, err := os.ReadFile(config.Hooks.State)
+	test.NoError(err)
+	test.Equal("{}", string(contents))
+
+	hooks, err = newWatchHooks(&Config{})
+	test.NoError(err)
+	test.Nil(hooks)
+}
+
+func TestWatchHooksNotify_Listed(t *testing.T) {
+	test := assert.New(t)
+
+	config := &Config{}
+	config.Hooks.Command = "cat > /dev/null"
+	config.Hooks.State = filepath.Join(t.TempDir(), "state")
+
+	hooks, err := newWatchHooks(config)
+	if !test.NoError(err) {
+		return
+	}
+
+	var (
+		server    = &serverTriggers{Server: Server{Name: "prod"}}
+		client    = &hooksTestClient{}
+		refreshed = map[string]hooksClient{"prod": client}
+	)
+
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(server, "1", "10", "1", "4"),
+		newWatchRow(server, "2", "20", "1", "2"),
+	}, refreshed)
+	test.NoError(err)
+	test.Len(hooks.problems, 2)
+
+	// listed triggers aren't checked, the deleted trigger is forgotten
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(server, "1", "12", "0", "4"),
+	}, refreshed)
+	test.NoError(err)
+	test.Equal(1, client.requests)
+	test.Empty(hooks.problems)
+}
+
+func TestWatchHooksNotify_FirstRun(t *testing.T) {
+	test := assert.New(t)
+
+	dir := t.TempDir()
+
+	config := &Config{}
+	config.Hooks.Command = "cat >> " + filepath.Join(dir, "events")
+	config.Hooks.State = filepath.Join(dir, "state")
+
+	hooks, err := newWatchHooks(config)
+	if !test.NoError(err) {
+		return
+	}
+
+	var (
+		prod = &serverTriggers{Server: Server{Name: "prod"}}
+		lab  = &serverTriggers{Server: Server{Name: "lab"}}
+	)
+
+	// existing problems are recorded without running hooks, lab isn't
+	// refreshed yet
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(prod, "1", "10", "1", "4"),
+	}, map[string]hooksClient{"prod": &hooksTestClient{}})
+	test.NoError(err)
+	test.Len(hooks.problems, 1)
+
+	_, err = os.Stat(filepath.Join(dir, "events"))
+	test.True(os.IsNotExist(err))
+
+	err = hooks.notify(context.Background(), []triggerRow{
+		newWatchRow(prod, "1", "10", "1", "4"),
+		newWatchRow(prod, "2", "20", "1", "4"),
+		newWatchRow(lab, "1", "10", "1", "4"),
+	}, map[string]hooksClient{
+		"prod": &hooksTestClient{},
+		"lab":  &hooksTestClient{},
+	})
+	test.NoError(err)
+	test.Len(hooks.problems, 3)
+
+	contents, err := os.ReadFile(filepath.Join(dir, "events"))
+	test.NoError(err)
+	test.Equal(1, strings.Count(string(contents), `"event":"problem"`))
+	test.Contains(string(contents), `"triggerid":"2"`)
+
+	// the state is written, problems are notified after restart
+	hooks, err = newWatchHooks(config)
+	if !test.NoError(err) {
+		return
+	}
+
+	test.Nil(hooks.seeded)
+	test.Len(hooks.problems, 3)
+}
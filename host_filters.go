@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+// hostFilters are --group, --template and --severity filters, identifiers of
+// groups and templates as well as names of severities are resolved on every
+// server separately since they differ between servers.
+type hostFilters struct {
+	groups    []string
+	templates []string
+	severity  string
+}
+
+func parseHostFilters(args map[string]interface{}) hostFilters {
+	var (
+		groups, _    = args["--group"].([]string)
+		templates, _ = args["--template"].([]string)
+		severity, _  = args["--severity"].(string)
+	)
+
+	return hostFilters{groups: groups, templates: templates, severity: severity}
+}
+
+func (filters hostFilters) empty() bool {
+	return len(filters.groups) == 0 && len(filters.templates) == 0 &&
+		filters.severity == ""
+}
+
+// resolve returns a copy of params with groupids, hostids and severities of
+// the filters on the server, it fails when no host can match the filters.
+func (filters hostFilters) resolve(
+	ctx context.Context,
+	client *zabbix.Zabbix,
+	params zabbix.Params,
+) (resolved zabbix.Params, err error) {
+	if filters.empty() {
+		return params, nil
+	}
+
+	resolved = zabbix.Params{}
+	for key, value := range params {
+		resolved[key] = value
+	}
+
+	if filters.severity != "" {
+		resolved["severities"], err = filters.severities(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filters.groups) > 0 {
+		groups, err := client.GetGroups(ctx, zabbix.Params{
+			"output":                 []string{"groupid", "name"},
+			"search":                 zabbix.Params{"name": filters.groups},
+			"searchByAny":            true,
+			"searchWildcardsEnabled": true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 {
+			return nil, fmt.Errorf(
+				"no host groups match %q", filters.groups,
+			)
+		}
+
+		identifiers := []string{}
+		for _, group := range groups {
+			identifiers = append(identifiers, group.ID)
+		}
+
+		resolved["groupids"] = identifiers
+	}
+
+	if len(filters.templates) > 0 {
+		templates, err := client.GetTemplates(ctx, zabbix.Params{
+			"output": []string{"templateid", "host", "name"},
+			"search": zabbix.Params{
+				"host": filters.templates,
+				"name": filters.templates,
+			},
+			"searchByAny":            true,
+			"searchWildcardsEnabled": true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(templates) == 0 {
+			return nil, fmt.Errorf(
+				"no templates match %q", filters.templates,
+			)
+		}
+
+		identifiers := []string{}
+		for _, template := range templates {
+			identifiers = append(identifiers, template.ID)
+		}
+
+		// templateids of trigger.get return triggers of templates themselves,
+		// triggers of linked hosts are requested by hostids instead
+		hosts, err := client.GetHosts(ctx, zabbix.Params{
+			"output":      []string{"hostid"},
+			"templateids": identifiers,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(hosts) == 0 {
+			return nil, fmt.Errorf(
+				"no hosts linked to templates %q", filters.templates,
+			)
+		}
+
+		identifiers = []string{}
+		for _, host := range hosts {
+			identifiers = append(identifiers, host.ID)
+		}
+
+		resolved["hostids"] = identifiers
+	}
+
+	return resolved, nil
+}
+
+// severities returns severities of the filter, names configured on the server
+// are requested only when the filter doesn't match default names.
+func (filters hostFilters) severities(
+	ctx context.Context,
+	client *zabbix.Zabbix,
+) ([]zabbix.Severity, error) {
+	severities, err := zabbix.ParseSeverityRange(filters.severity, nil)
+	if err == nil {
+		return severities, nil
+	}
+
+	names, err := client.GetSeverityNames(ctx)
+	if err != nil {
+		return nil, karma.Format(err, "can't obtain names of severities")
+	}
+
+	return zabbix.ParseSeverityRange(filters.severity, names)
+}
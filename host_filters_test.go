@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+// newHostFiltersTestServer returns a server with the group 'Web' (1) and the
+// template 'Linux' (2) linked to hosts 3 and 4, other names match nothing.
+func newHostFiltersTestServer(t *testing.T, linked bool) *httptest.Server {
+	result := func(request testRequest) interface{} {
+		search, _ := request.Params["search"].(map[string]interface{})
+
+		switch request.Method {
+		case "apiinfo.version":
+			return "6.0.0"
+
+		case "hostgroup.get":
+			if search["name"].([]interface{})[0] == "Web" {
+				return []interface{}{map[string]string{
+					"groupid": "1", "name": "Web",
+				}}
+			}
+
+			return []interface{}{}
+
+		case "template.get":
+			if search["host"].([]interface{})[0] == "Linux" {
+				return []interface{}{map[string]string{
+					"templateid": "2", "host": "Linux", "name": "Linux",
+				}}
+			}
+
+			return []interface{}{}
+
+		case "host.get":
+			if !linked {
+				return []interface{}{}
+			}
+
+			return []interface{}{
+				map[string]string{"hostid": "3"},
+				map[string]string{"hostid": "4"},
+			}
+		}
+
+		t.Errorf("unexpected method %s", request.Method)
+
+		return nil
+	}
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var request testRequest
+			json.NewDecoder(r.Body).Decode(&request)
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"result":  result(request),
+				"id":      request.ID,
+			})
+		},
+	))
+}
+
+func TestHostFiltersResolve(t *testing.T) {
+	test := assert.New(t)
+
+	testcases := []struct {
+		filters hostFilters
+		linked  bool
+		params  zabbix.Params
+		err     string
+	}{
+		{
+			filters: hostFilters{},
+			params:  zabbix.Params{"limit": "0"},
+		},
+		{
+			filters: hostFilters{groups: []string{"Web"}},
+			params: zabbix.Params{
+				"limit":    "0",
+				"groupids": []string{"1"},
+			},
+		},
+		{
+			filters: hostFilters{
+				groups:    []string{"Web"},
+				templates: []string{"Linux"},
+			},
+			linked: true,
+			params: zabbix.Params{
+				"limit":    "0",
+				"groupids": []string{"1"},
+				"hostids":  []string{"3", "4"},
+			},
+		},
+		{
+			filters: hostFilters{severity: "warn..high"},
+			params: zabbix.Params{
+				"limit": "0",
+				"severities": []zabbix.Severity{
+					zabbix.SeverityWarning,
+					zabbix.SeverityAverage,
+					zabbix.SeverityHigh,
+				},
+			},
+		},
+		{
+			filters: hostFilters{templates: []string{"Linux"}},
+			err:     `no hosts linked to templates ["Linux"]`,
+		},
+		{
+			filters: hostFilters{groups: []string{"Databases"}},
+			err:     "no host groups match",
+		},
+		{
+			filters: hostFilters{templates: []string{"Windows"}},
+			err:     "no templates match",
+		},
+	}
+
+	for _, testcase := range testcases {
+		testserver := newHostFiltersTestServer(t, testcase.linked)
+
+		client, err := zabbix.NewZabbix(context.Background(), zabbix.Options{
+			Address: testserver.URL,
+			Token:   "token",
+		})
+		if !test.NoError(err) {
+			testserver.Close()
+			return
+		}
+
+		params := zabbix.Params{"limit": "0"}
+
+		resolved, err := testcase.filters.resolve(
+			context.Background(), client, params,
+		)
+
+		testserver.Close()
+
+		if testcase.err != "" {
+			if test.Error(err) {
+				test.Contains(err.Error(), testcase.err)
+			}
+
+			continue
+		}
+
+		test.NoError(err)
+		test.Equal(zabbix.Params{"limit": "0"}, params)
+		test.Equal(testcase.params, resolved)
+	}
+}
@@ -7,6 +7,14 @@ import (
 
 	"github.com/kovetskiy/lorg"
 	"github.com/kovetskiy/spinner-go"
+	"github.com/lasseoe/zabbixctl/zabbix"
+)
+
+const (
+	exitFailure = 1
+
+	// exitAPIError is used when the Zabbix API rejected the request
+	exitAPIError = 2
 )
 
 func getLogger() (logger *lorg.Log) {
@@ -16,8 +24,8 @@ func getLogger() (logger *lorg.Log) {
 	return logger
 }
 
-// use fatalf or fatalln
-func fatal(format string, values ...interface{}) {
+// use fatalln
+func fatal(code int, format string, values ...interface{}) {
 	if spinner.IsActive() {
 		spinner.Stop()
 	}
@@ -27,16 +35,23 @@ func fatal(format string, values ...interface{}) {
 		format = fmt.Sprintf("%s#%d: %v", file, ln, format)
 	}
 	fmt.Fprintf(os.Stderr, format+"\n", values...)
-	os.Exit(1)
+	os.Exit(code)
 }
 
-//lint:ignore U1000 Ignore unused function temporarily for debugging
-func fatalf(format string, values ...interface{}) {
-	fatal(format, values...)
+func fatalln(value interface{}) {
+	if _, ok := value.(*zabbix.APIError); ok {
+		fatal(exitAPIError, "%s", value)
+	}
+
+	fatal(exitFailure, "%s", value)
 }
 
-func fatalln(value interface{}) {
-	fatal("%s", value)
+func warningln(value interface{}) {
+	if spinner.IsActive() {
+		spinner.Stop()
+	}
+
+	logger.Warning(value)
 }
 
 // use debugf or debugln
@@ -65,6 +80,7 @@ func trace(format string, values ...interface{}) {
 	logger.Tracef(format, values...)
 }
 
+//lint:ignore U1000 Ignore unused function temporarily for debugging
 func tracef(format string, values ...interface{}) {
 	trace(format, values...)
 }
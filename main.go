@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kovetskiy/godocs"
 	"github.com/kovetskiy/lorg"
 	"github.com/kovetskiy/spinner-go"
+	"github.com/lasseoe/zabbixctl/zabbix"
 	"github.com/reconquest/karma-go"
 )
 
 var (
 	debugMode bool
-	traceMode bool
+
+	// quietMode suppresses spinners for the machine-readable output
+	quietMode bool
 
 	logger = getLogger()
 )
@@ -35,10 +43,16 @@ func main() {
 		logger.SetLevel(lorg.LevelDebug)
 	case 2:
 		debugMode = true
-		traceMode = true
 		logger.SetLevel(lorg.LevelTrace)
 	}
 
+	output, err := parseOutputOptions(args)
+	if err != nil {
+		fatalln(err)
+	}
+
+	quietMode = output.format != outputTable
+
 	config, err := NewConfig(args["--config"].(string))
 	if err != nil {
 		fatalln(
@@ -49,28 +63,75 @@ func main() {
 		)
 	}
 
-	zabbix, err := NewZabbix(
-		config.Server.Address,
-		config.Server.Username,
-		config.Server.Password,
-		config.Server.Insecure,
-		config.Session.Path,
-	)
-	if err != nil {
-		fatalln(err)
+	if args["config"].(bool) {
+		err = handleConfig(config, args)
+		if err != nil {
+			fatalln(err)
+		}
+
+		return
+	}
+
+	// events and api calls don't match the /<pattern> argument
+	if !args["events"].(bool) && !args["api"].(bool) {
+		err = setSearchOptions(config, args)
+		if err != nil {
+			fatalln(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var servers []Server
+
+	if args["--all-contexts"].(bool) {
+		servers, err = connectServers(ctx, config)
+		if err != nil {
+			fatalln(err)
+		}
+	} else {
+		contextName, _ := args["--context"].(string)
+
+		server, err := config.GetContext(contextName)
+		if err != nil {
+			fatalln(
+				karma.Format(
+					err,
+					"problem with configuration",
+				),
+			)
+		}
+
+		client, err := newZabbix(ctx, server)
+		if err != nil {
+			fatalln(err)
+		}
+
+		servers = []Server{{Name: server.Name, Client: client}}
 	}
 
+	client := servers[0].Client
+
 	switch {
 	case args["--triggers"].(bool):
-		err = handleTriggers(zabbix, config, args)
+		err = handleTriggers(ctx, servers, config, args)
+	case args["--problems"].(bool):
+		err = handleProblems(ctx, servers, config, args)
 	case args["--latest-data"].(bool):
-		err = handleLatestData(zabbix, config, args)
+		err = handleLatestData(ctx, servers, config, args)
 	case args["--groups"].(bool):
-		err = handleUsersGroups(zabbix, config, args)
+		err = handleUsersGroups(ctx, client, config, args)
 	case args["--maintenances"].(bool):
-		err = handleMaintenances(zabbix, config, args)
+		err = handleMaintenances(ctx, client, config, args)
 	case args["--hosts"].(bool):
-		err = handleHosts(zabbix, config, args)
+		err = handleHosts(ctx, client, config, args)
+	case args["events"].(bool):
+		err = handleEvents(ctx, servers, config, args)
+	case args["report"].(bool):
+		err = handleReport(ctx, servers, config, args)
+	case args["api"].(bool):
+		err = handleAPI(ctx, client, config, args)
 
 	}
 
@@ -78,3 +139,29 @@ func main() {
 		fatalln(err)
 	}
 }
+
+func newZabbix(ctx context.Context, server *Context) (*zabbix.Zabbix, error) {
+	insecure, err := strconv.ParseBool(strings.ToLower(server.Server.Insecure))
+	if err != nil {
+		return nil, karma.Format(
+			err,
+			"can't parse insecure config flag, expected boolean, got '%s'",
+			server.Server.Insecure,
+		)
+	}
+
+	options := zabbix.Options{
+		Address:     server.Server.Address,
+		Username:    server.Server.Username,
+		Password:    server.Server.Password,
+		Token:       server.Server.Token,
+		Insecure:    insecure,
+		SessionFile: server.SessionPath,
+	}
+
+	if debugMode {
+		options.Logger = logger
+	}
+
+	return zabbix.NewZabbix(ctx, options)
+}
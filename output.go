@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+	outputYAML   = "yaml"
+	outputCSV    = "csv"
+)
+
+// outputOptions describe how listings are printed: the machine-readable
+// format, the template given by --format or columns given by --columns.
+type outputOptions struct {
+	format   string
+	template *template.Template
+	columns  []string
+}
+
+// column is a named column of a listing which can be selected by --columns.
+type column[T any] struct {
+	name  string
+	value func(T) string
+}
+
+func parseOutputOptions(args map[string]interface{}) (outputOptions, error) {
+	var (
+		options = outputOptions{
+			format: args["--output"].(string),
+		}
+
+		text, _    = args["--format"].(string)
+		columns, _ = args["--columns"].(string)
+	)
+
+	switch options.format {
+	case outputTable, outputJSON, outputNDJSON, outputYAML, outputCSV:
+	default:
+		return options, fmt.Errorf(
+			"unexpected output format '%s', expected one of: %s, %s, %s, %s, %s",
+			options.format,
+			outputTable, outputJSON, outputNDJSON, outputYAML, outputCSV,
+		)
+	}
+
+	// csv has columns as the table, all columns are printed by default
+	if options.format == outputCSV && text != "" {
+		return options, fmt.Errorf(
+			"--format can't be used with --output %s",
+			options.format,
+		)
+	}
+
+	if text != "" || columns != "" {
+		if options.format != outputTable && options.format != outputCSV {
+			return options, fmt.Errorf(
+				"--format and --columns can't be used with --output %s",
+				options.format,
+			)
+		}
+
+		if text != "" && columns != "" {
+			return options, fmt.Errorf(
+				"--format and --columns are mutually exclusive",
+			)
+		}
+	}
+
+	if text != "" {
+		var err error
+		options.template, err = template.New("format").Parse(text)
+		if err != nil {
+			return options, fmt.Errorf("can't parse --format template: %s", err)
+		}
+	}
+
+	if columns != "" {
+		for _, name := range strings.Split(columns, ",") {
+			options.columns = append(
+				options.columns,
+				strings.ToLower(strings.TrimSpace(name)),
+			)
+		}
+	}
+
+	return options, nil
+}
+
+// custom reports whether the listing should be printed by printRows instead
+// of the default table.
+func (options outputOptions) custom() bool {
+	return options.format != outputTable ||
+		options.template != nil ||
+		len(options.columns) > 0
+}
+
+// printRows prints rows of the listing according to the output options,
+// the template is executed for every row and columns are looked up by name
+// in the given columns of the listing, csv has all columns by default.
+func printRows[T any](
+	options outputOptions,
+	columns []column[T],
+	rows []T,
+) error {
+	switch {
+	case options.format == outputCSV:
+		if len(options.columns) == 0 {
+			return printCSV(columns, rows)
+		}
+
+	case options.format != outputTable:
+		return printObjects(options.format, rows)
+
+	case options.template != nil:
+		for _, row := range rows {
+			err := options.template.Execute(os.Stdout, row)
+			if err != nil {
+				return fmt.Errorf("can't execute --format template: %s", err)
+			}
+
+			fmt.Println()
+		}
+
+		return nil
+	}
+
+	selected := []column[T]{}
+	for _, name := range options.columns {
+		found := false
+		for _, column := range columns {
+			if column.name == name {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			names := []string{}
+			for _, column := range columns {
+				names = append(names, column.name)
+			}
+
+			return fmt.Errorf(
+				"unexpected column '%s', expected one of: %s",
+				name, strings.Join(names, ", "),
+			)
+		}
+	}
+
+	if options.format == outputCSV {
+		return printCSV(selected, rows)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 1, 4, 2, ' ', 0)
+	for _, row := range rows {
+		values := []string{}
+		for _, column := range selected {
+			values = append(values, column.value(row))
+		}
+
+		fmt.Fprintln(table, strings.Join(values, "\t"))
+	}
+
+	return table.Flush()
+}
+
+// printCSV writes rows as csv with the header of column names.
+func printCSV[T any](columns []column[T], rows []T) error {
+	writer := csv.NewWriter(os.Stdout)
+
+	header := []string{}
+	for _, column := range columns {
+		header = append(header, column.name)
+	}
+
+	err := writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		values := []string{}
+		for _, column := range columns {
+			values = append(values, column.value(row))
+		}
+
+		err = writer.Write(values)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// printObjects writes slice of objects or a single object to stdout using
+// the given machine-readable format, field names are taken from json tags.
+func printObjects(format string, objects interface{}) error {
+	value := reflect.ValueOf(objects)
+
+	// empty output should be a list, not null
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		objects = []interface{}{}
+	}
+
+	switch format {
+	case outputNDJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		if value.Kind() != reflect.Slice {
+			return encoder.Encode(objects)
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			err := encoder.Encode(value.Index(i).Interface())
+			if err != nil {
+				return err
+			}
+		}
+
+	case outputYAML:
+		// yaml is produced from json to keep the same field names
+		buffer, err := json.Marshal(objects)
+		if err != nil {
+			return err
+		}
+
+		var generic interface{}
+		err = json.Unmarshal(buffer, &generic)
+		if err != nil {
+			return err
+		}
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+
+		err = encoder.Encode(generic)
+		if err != nil {
+			return err
+		}
+
+		return encoder.Close()
+
+	default:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(objects)
+	}
+
+	return nil
+}
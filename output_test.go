@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutputOptions(t *testing.T) {
+	test := assert.New(t)
+
+	options, err := parseOutputOptions(map[string]interface{}{
+		"--output":  "table",
+		"--columns": "Host, severity,age",
+	})
+	test.NoError(err)
+	test.True(options.custom())
+	test.Equal([]string{"host", "severity", "age"}, options.columns)
+
+	options, err = parseOutputOptions(map[string]interface{}{
+		"--output": "table",
+		"--format": "{{.Host}} {{.Severity}}",
+	})
+	test.NoError(err)
+	test.NotNil(options.template)
+
+	options, err = parseOutputOptions(map[string]interface{}{
+		"--output": "table",
+	})
+	test.NoError(err)
+	test.False(options.custom())
+
+	_, err = parseOutputOptions(map[string]interface{}{
+		"--output":  "json",
+		"--columns": "host",
+	})
+	test.Error(err)
+
+	_, err = parseOutputOptions(map[string]interface{}{
+		"--output": "table",
+		"--format": "{{.Host",
+	})
+	test.Error(err)
+
+	_, err = parseOutputOptions(map[string]interface{}{
+		"--output": "xml",
+	})
+	test.Error(err)
+
+	options, err = parseOutputOptions(map[string]interface{}{
+		"--output":  "csv",
+		"--columns": "host",
+	})
+	test.NoError(err)
+	test.True(options.custom())
+	test.Equal([]string{"host"}, options.columns)
+
+	_, err = parseOutputOptions(map[string]interface{}{
+		"--output": "csv",
+		"--format": "{{.Host}}",
+	})
+	test.Error(err)
+}
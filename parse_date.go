@@ -7,6 +7,10 @@ import (
 	karma "github.com/reconquest/karma-go"
 )
 
+// defaultSince is used when --since isn't set for triggers, events and
+// reports, problems of any age are listed by default.
+const defaultSince = "7 days ago"
+
 func parseDate(date string) (int64, error) {
 	var dateUnix int64
 
@@ -41,3 +45,15 @@ func parseDateTime(date string) (int64, error) {
 
 	return dateParse.Unix(), nil
 }
+
+// parseDateRange returns the start and the end of the period named by the
+// date, e.g. 'last month' is the whole month, '7 days ago' is the whole day.
+func parseDateRange(date string) (int64, int64, error) {
+	timeNow := time.Now()
+	dateRange, err := anytime.ParseRange(date, timeNow)
+	if err != nil {
+		return 0, 0, karma.Format(err, "can't parse datetime '%s'", date)
+	}
+
+	return dateRange.Start().Unix(), dateRange.End().Unix(), nil
+}
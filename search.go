@@ -1,39 +1,160 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
 
-func parseSearchQuery(targets []string) (words []string, pattern string) {
+const (
+	matchFuzzy = "fuzzy"
+	matchRegex = "regex"
+	matchGlob  = "glob"
+	matchExact = "exact"
+)
+
+// searchMode is how the /<pattern> argument matches, it's set by --match or
+// the [search] section of the configuration.
+var searchMode = matchFuzzy
+
+// searchCaseSensitive disables case folding of the /<pattern> argument.
+var searchCaseSensitive bool
+
+// setSearchOptions sets the search mode and case sensitivity given by flags,
+// the configuration is used when they are not set.
+func setSearchOptions(config *Config, args map[string]interface{}) error {
 	var (
-		search bool
-		query  []string
+		mode, _          = args["--match"].(string)
+		caseSensitive, _ = args["--case-sensitive"].(bool)
 	)
 
-	for _, target := range targets {
-		if strings.HasPrefix(target, "/") {
-			query = append(
-				query,
-				strings.TrimPrefix(target, "/"),
-			)
+	if mode == "" {
+		mode = config.Search.Mode
+	}
 
-			search = true
+	switch mode {
+	case "":
+		mode = matchFuzzy
+	case matchFuzzy, matchRegex, matchGlob, matchExact:
+	default:
+		return fmt.Errorf(
+			"unexpected match mode '%s', expected one of: %s, %s, %s, %s",
+			mode, matchFuzzy, matchRegex, matchGlob, matchExact,
+		)
+	}
+
+	searchMode = mode
+	searchCaseSensitive = caseSensitive || config.Search.CaseSensitive
 
-			continue
+	// regular expressions are verified before any request is made
+	_, query := parseSearchQuery(args)
+	for _, pattern := range append([]string{query.pattern}, query.excludes...) {
+		_, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("can't parse search pattern: %s", err)
 		}
+	}
+
+	return nil
+}
 
-		if search {
-			query = append(query, target)
-		} else {
+// searchQuery is the /<pattern> argument along with patterns excluded by
+// !/<pattern> arguments and --exclude.
+type searchQuery struct {
+	// text is the query of the /<pattern> argument as given.
+	text     string
+	pattern  string
+	excludes []string
+}
+
+// parseSearchQuery returns words preceding the query and the query, words
+// following /<pattern> or !/<pattern> belong to it.
+func parseSearchQuery(args map[string]interface{}) (words []string, query searchQuery) {
+	var (
+		targets, _  = args["<pattern>"].([]string)
+		excludes, _ = args["--exclude"].([]string)
+
+		include  []string
+		excluded [][]string
+		current  *[]string
+	)
+
+	for _, target := range targets {
+		switch {
+		case strings.HasPrefix(target, "/"):
+			include = append(include, strings.TrimPrefix(target, "/"))
+			current = &include
+
+		case strings.HasPrefix(target, "!/"):
+			excluded = append(excluded, []string{strings.TrimPrefix(target, "!/")})
+			current = &excluded[len(excluded)-1]
+
+		case current != nil:
+			*current = append(*current, target)
+
+		default:
 			words = append(words, target)
 		}
 	}
 
-	return words, getSearchPattern(query)
+	for _, exclude := range excludes {
+		excluded = append(excluded, []string{strings.TrimPrefix(exclude, "/")})
+	}
+
+	query.text = strings.Join(include, " ")
+	query.pattern = getSearchPattern(include)
+
+	for _, exclude := range excluded {
+		if pattern := getSearchPattern(exclude); pattern != "" {
+			query.excludes = append(query.excludes, pattern)
+		}
+	}
+
+	return words, query
 }
 
+func (query searchQuery) empty() bool {
+	return query.pattern == "" && len(query.excludes) == 0
+}
+
+// match reports whether the target matches the pattern and none of excluded
+// patterns.
+func (query searchQuery) match(target string) bool {
+	if query.pattern != "" && !matchPattern(query.pattern, target) {
+		return false
+	}
+
+	for _, exclude := range query.excludes {
+		if matchPattern(exclude, target) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getSearchPattern returns the regular expression of the query for the
+// search mode: fuzzy matches letters of the query in order with anything
+// between them, regex is the query itself, glob has '*' for any characters
+// and '?' for a single character, exact has no special characters. Patterns
+// aren't anchored, targets consist of several fields, e.g. the event, host
+// and description of triggers, so any part of the target matches.
 func getSearchPattern(query []string) string {
+	switch searchMode {
+	case matchRegex:
+		return strings.Join(query, " ")
+
+	case matchGlob:
+		pattern := regexp.QuoteMeta(strings.Join(query, " "))
+		pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+		pattern = strings.ReplaceAll(pattern, `\?`, ".")
+
+		return pattern
+
+	case matchExact:
+		return regexp.QuoteMeta(strings.Join(query, " "))
+	}
+
 	letters := strings.Split(
 		strings.Replace(
 			strings.Join(query, ""),
@@ -51,8 +172,11 @@ func getSearchPattern(query []string) string {
 }
 
 func matchPattern(pattern, target string) bool {
-	match, err := regexp.MatchString(strings.ToLower(pattern),
-		strings.ToLower(target))
+	if !searchCaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+
+	match, err := regexp.MatchString(pattern, target)
 	if err != nil {
 		debugf("Error: %+v", err)
 	}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPattern(t *testing.T) {
+	test := assert.New(t)
+
+	defer func() {
+		searchMode = matchFuzzy
+		searchCaseSensitive = false
+	}()
+
+	testcases := []struct {
+		mode          string
+		caseSensitive bool
+		query         []string
+		target        string
+		match         bool
+	}{
+		{matchFuzzy, false, []string{"cpu"}, "Check process uptime", true},
+		{matchExact, false, []string{"cpu"}, "Check process uptime", false},
+		{matchExact, false, []string{"cpu"}, "High CPU load", true},
+		{matchExact, true, []string{"cpu"}, "High CPU load", false},
+		{matchExact, false, []string{"cpu", "load"}, "High CPU load", true},
+		{matchExact, false, []string{"cpu.load"}, "High CPU load", false},
+		{matchGlob, false, []string{"db?*replication"}, "db1 MySQL replication", true},
+		{matchGlob, false, []string{"db?*replication"}, "db MySQL", false},
+		{matchGlob, false, []string{"web*"}, "12 proxy web1 down", true},
+		{matchGlob, false, []string{"db?-*"}, "replica db2-b", true},
+		{matchRegex, false, []string{`^db\d+ `}, "db12 MySQL replication", true},
+		{matchRegex, false, []string{`^db\d+ `}, "web12 MySQL", false},
+		{matchRegex, true, []string{`^DB`}, "db12 MySQL", false},
+	}
+
+	for _, testcase := range testcases {
+		searchMode = testcase.mode
+		searchCaseSensitive = testcase.caseSensitive
+
+		_, query := parseSearchQuery(map[string]interface{}{
+			"<pattern>": append(
+				[]string{"host", "/" + testcase.query[0]}, testcase.query[1:]...,
+			),
+		})
+
+		test.Equal(
+			testcase.match, query.match(testcase.target),
+			"%s %q %q", testcase.mode, testcase.query, testcase.target,
+		)
+	}
+}
+
+func TestParseSearchQuery_Excludes(t *testing.T) {
+	test := assert.New(t)
+
+	testcases := []struct {
+		args   map[string]interface{}
+		words  []string
+		target string
+		match  bool
+	}{
+		{
+			map[string]interface{}{"<pattern>": []string{"host", "!/backup"}},
+			[]string{"host"}, "Backup job failed", false,
+		},
+		{
+			map[string]interface{}{"<pattern>": []string{"!/backup"}},
+			nil, "High CPU load", true,
+		},
+		{
+			map[string]interface{}{
+				"<pattern>": []string{"/disk", "!/backup"},
+			},
+			nil, "Free disk space is low", true,
+		},
+		{
+			map[string]interface{}{
+				"<pattern>": []string{"/disk", "!/backup"},
+			},
+			nil, "Disk of backup volume is full", false,
+		},
+		{
+			map[string]interface{}{
+				"<pattern>": []string{"/disk", "!/backup", "volume"},
+			},
+			nil, "Disk of backup host is full", true,
+		},
+		{
+			map[string]interface{}{
+				"<pattern>": []string{"!/backup", "/disk"},
+				"--exclude": []string{"tmp", "/cpu"},
+			},
+			nil, "Free disk space on /tmp is low", false,
+		},
+		{
+			map[string]interface{}{
+				"<pattern>": []string{"db1", "/disk"},
+				"--exclude": []string{"tmp", "/cpu"},
+			},
+			[]string{"db1"}, "Disk of CPU cache", false,
+		},
+		{
+			map[string]interface{}{
+				"<pattern>": []string{"db1", "/disk"},
+				"--exclude": []string{"tmp", "/cpu"},
+			},
+			[]string{"db1"}, "Free disk space is low", true,
+		},
+	}
+
+	for _, testcase := range testcases {
+		words, query := parseSearchQuery(testcase.args)
+
+		test.Equal(testcase.words, words, "%v", testcase.args)
+		test.Equal(
+			testcase.match, query.match(testcase.target),
+			"%v %q", testcase.args, testcase.target,
+		)
+	}
+}
+
+func TestSetSearchOptions(t *testing.T) {
+	test := assert.New(t)
+
+	defer func() {
+		searchMode = matchFuzzy
+		searchCaseSensitive = false
+	}()
+
+	config := &Config{}
+	config.Search.Mode = matchGlob
+
+	test.NoError(setSearchOptions(config, map[string]interface{}{}))
+	test.Equal(matchGlob, searchMode)
+	test.False(searchCaseSensitive)
+
+	test.NoError(setSearchOptions(config, map[string]interface{}{
+		"--match":          matchRegex,
+		"--case-sensitive": true,
+		"<pattern>":        []string{"/^db"},
+	}))
+	test.Equal(matchRegex, searchMode)
+	test.True(searchCaseSensitive)
+
+	test.Error(setSearchOptions(config, map[string]interface{}{
+		"--match":   matchRegex,
+		"<pattern>": []string{"/(("},
+	}))
+
+	test.Error(setSearchOptions(config, map[string]interface{}{
+		"--match":   matchRegex,
+		"--exclude": []string{"(("},
+	}))
+
+	test.Error(setSearchOptions(&Config{}, map[string]interface{}{
+		"--match": "soundex",
+	}))
+}
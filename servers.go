@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+// Server is a connected Zabbix server, Name is the name of its context.
+type Server struct {
+	Name   string
+	Client *zabbix.Zabbix
+}
+
+// connectServers connects to servers of every configured context in parallel,
+// servers which can't be connected to are reported and skipped.
+func connectServers(ctx context.Context, config *Config) ([]Server, error) {
+	names := config.GetContexts()
+	if len(names) == 0 {
+		return nil, errors.New(
+			"no contexts configured, use [servers.<name>] sections",
+		)
+	}
+
+	servers := make([]Server, len(names))
+	for index, name := range names {
+		servers[index].Name = name
+	}
+
+	var errs []error
+
+	err := withSpinner(
+		":: Connecting to configured servers",
+		func() error {
+			errs = fanOut(len(names), func(index int) error {
+				// credentials of environment variables belong to the
+				// current context only
+				server, err := config.getContext(
+					names[index], names[index] == config.CurrentContext,
+				)
+				if err != nil {
+					return err
+				}
+
+				client, err := newZabbix(ctx, server)
+				if err != nil {
+					return err
+				}
+
+				servers[index].Client = client
+
+				return nil
+			})
+
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	servers, err = skipFailed(servers, servers, errs, "can't connect")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(servers) == 0 {
+		return nil, errors.New("none of configured servers is reachable")
+	}
+
+	return servers, nil
+}
+
+// reportSkipped reports the failure of the server skipped by skipFailed as a
+// warning, --watch and --interactive replace it since warnings would break
+// their screens.
+var reportSkipped = func(server Server, reason string, err error) {
+	warningln(
+		karma.Format(
+			err,
+			"%s, context %s is skipped", reason, server.Name,
+		),
+	)
+}
+
+// skipFailed returns results of servers which have no corresponding error in
+// errs, failures are reported by reportSkipped. The error of the only server
+// is returned as is, so single server commands fail as usual.
+func skipFailed[T any](
+	servers []Server,
+	results []T,
+	errs []error,
+	reason string,
+) ([]T, error) {
+	if len(servers) == 1 {
+		return results, errs[0]
+	}
+
+	succeeded := []T{}
+	for index, server := range servers {
+		if errs[index] != nil {
+			reportSkipped(server, reason, errs[index])
+			continue
+		}
+
+		succeeded = append(succeeded, results[index])
+	}
+
+	return succeeded, nil
+}
+
+// fanOut calls method for every index in parallel, errors are returned in
+// the same order.
+func fanOut(amount int, method func(index int) error) []error {
+	var (
+		errs  = make([]error, amount)
+		group sync.WaitGroup
+	)
+
+	for index := 0; index < amount; index++ {
+		group.Add(1)
+
+		go func(index int) {
+			defer group.Done()
+
+			errs[index] = method(index)
+		}(index)
+	}
+
+	group.Wait()
+
+	return errs
+}
@@ -8,6 +8,10 @@ import (
 )
 
 func withSpinner(status string, method func() error) error {
+	if quietMode {
+		return method()
+	}
+
 	if debugMode {
 		fmt.Fprintln(os.Stderr, status)
 		return method()
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+)
+
+// summaryCount is the amount of problems of the severity, the host group or
+// the host.
+type summaryCount struct {
+	Name     string `json:"name"`
+	Problems int    `json:"problems"`
+}
+
+// triggersSummary are amounts of listed triggers in a problem state, host
+// groups and hosts are limited to the top ones with most problems.
+type triggersSummary struct {
+	Problems       int            `json:"problems"`
+	Acknowledged   int            `json:"acknowledged"`
+	Unacknowledged int            `json:"unacknowledged"`
+	Severities     []summaryCount `json:"severities"`
+	Servers        []summaryCount `json:"servers,omitempty"`
+	Groups         []summaryCount `json:"groups"`
+	Hosts          []summaryCount `json:"hosts"`
+
+	top int
+}
+
+// newTriggersSummary counts problems of the rows, names of host groups and
+// hosts are prefixed by the server name when servers of all contexts are
+// listed, top limits host groups and hosts, zero means no limit.
+func newTriggersSummary(
+	rows []triggerRow,
+	top int,
+	allContexts bool,
+) *triggersSummary {
+	var (
+		summary    = &triggersSummary{top: top}
+		severities = map[zabbix.Severity]int{}
+		servers    = map[string]int{}
+		groups     = map[string]int{}
+		hosts      = map[string]int{}
+	)
+
+	for _, row := range rows {
+		trigger := row.trigger
+		if trigger.Value != "1" {
+			continue
+		}
+
+		summary.Problems++
+
+		if trigger.LastEvent.Acknowledged == "1" {
+			summary.Acknowledged++
+		} else {
+			summary.Unacknowledged++
+		}
+
+		severities[trigger.Severity()]++
+
+		var prefix string
+		if allContexts {
+			servers[row.Name]++
+			prefix = row.Name + ": "
+		}
+
+		for _, group := range trigger.Groups {
+			groups[prefix+group.Name]++
+		}
+
+		for _, host := range trigger.Hosts {
+			hosts[prefix+host.Name]++
+		}
+	}
+
+	for severity := zabbix.SeverityDisaster; severity >= zabbix.SeverityNotClassified; severity-- {
+		summary.Severities = append(summary.Severities, summaryCount{
+			Name:     severity.String(),
+			Problems: severities[severity],
+		})
+	}
+
+	if allContexts {
+		summary.Servers = topCounts(servers, 0)
+	}
+
+	summary.Groups = topCounts(groups, top)
+	summary.Hosts = topCounts(hosts, top)
+
+	return summary
+}
+
+// topCounts returns counts ordered by the amount of problems, names with the
+// same amount are ordered alphabetically.
+func topCounts(counts map[string]int, top int) []summaryCount {
+	result := []summaryCount{}
+	for name, problems := range counts {
+		result = append(result, summaryCount{Name: name, Problems: problems})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Problems != result[j].Problems {
+			return result[i].Problems > result[j].Problems
+		}
+
+		return result[i].Name < result[j].Name
+	})
+
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+
+	return result
+}
+
+func (summary *triggersSummary) write(writer io.Writer) error {
+	table := tabwriter.NewWriter(writer, 1, 4, 2, ' ', 0)
+
+	fmt.Fprintf(
+		table,
+		"Problems: %d, acknowledged: %d, not acknowledged: %d\n",
+		summary.Problems, summary.Acknowledged, summary.Unacknowledged,
+	)
+
+	sections := []struct {
+		title  string
+		counts []summaryCount
+	}{
+		{"By severity", summary.Severities},
+		{"By server", summary.Servers},
+		{summary.title("host groups"), summary.Groups},
+		{summary.title("hosts"), summary.Hosts},
+	}
+
+	for _, section := range sections {
+		if len(section.counts) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(table, "\n%s:\n", section.title)
+
+		for _, count := range section.counts {
+			fmt.Fprintf(table, "  %s\t%d\n", count.Name, count.Problems)
+		}
+	}
+
+	return table.Flush()
+}
+
+func (summary *triggersSummary) title(name string) string {
+	if summary.top > 0 {
+		return fmt.Sprintf("Top %d %s", summary.top, name)
+	}
+
+	return "By " + name
+}
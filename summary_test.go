@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSummaryRow(
+	server *serverTriggers,
+	value, priority, acknowledged, host string,
+	groups ...string,
+) triggerRow {
+	row := newWatchRow(server, "1", "10", value, priority)
+	row.trigger.LastEvent.Acknowledged = acknowledged
+	row.trigger.Hosts = append(row.trigger.Hosts, struct {
+		Hostid string `json:"hostid"`
+		Name   string `json:"name"`
+	}{Name: host})
+
+	for _, group := range groups {
+		row.trigger.Groups = append(row.trigger.Groups, zabbix.Group{Name: group})
+	}
+
+	return row
+}
+
+func TestTriggersSummary(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		prod = &serverTriggers{Server: Server{Name: "prod"}}
+		lab  = &serverTriggers{Server: Server{Name: "lab"}}
+	)
+
+	rows := []triggerRow{
+		newSummaryRow(prod, "1", "4", "1", "db1", "Databases", "Linux"),
+		newSummaryRow(prod, "1", "4", "0", "db1", "Databases", "Linux"),
+		newSummaryRow(prod, "1", "2", "0", "web1", "Web", "Linux"),
+		newSummaryRow(prod, "0", "5", "0", "web2", "Web", "Linux"),
+		newSummaryRow(lab, "1", "3", "0", "db1", "Databases"),
+	}
+
+	summary := newTriggersSummary(rows, 2, false)
+
+	test.Equal(4, summary.Problems)
+	test.Equal(1, summary.Acknowledged)
+	test.Equal(3, summary.Unacknowledged)
+	test.Equal([]summaryCount{
+		{"DISASTER", 0},
+		{"HIGH", 2},
+		{"AVG", 1},
+		{"WARN", 1},
+		{"INFO", 0},
+		{"UNKNOWN", 0},
+	}, summary.Severities)
+	test.Empty(summary.Servers)
+	test.Equal([]summaryCount{{"Databases", 3}, {"Linux", 3}}, summary.Groups)
+	test.Equal([]summaryCount{{"db1", 3}, {"web1", 1}}, summary.Hosts)
+
+	summary = newTriggersSummary(rows, 0, true)
+
+	test.Equal([]summaryCount{{"prod", 3}, {"lab", 1}}, summary.Servers)
+	test.Equal([]summaryCount{
+		{"prod: db1", 2},
+		{"lab: db1", 1},
+		{"prod: web1", 1},
+	}, summary.Hosts)
+
+	var buffer bytes.Buffer
+
+	summary = newTriggersSummary(rows[2:3], 1, false)
+	test.NoError(summary.write(&buffer))
+	test.Equal(`Problems: 1, acknowledged: 0, not acknowledged: 1
+
+By severity:
+  DISASTER  0
+  HIGH      0
+  AVG       0
+  WARN      1
+  INFO      0
+  UNKNOWN   0
+
+Top 1 host groups:
+  Linux  1
+
+Top 1 hosts:
+  web1  1
+`, buffer.String())
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+)
+
+// tagOperators are operators of --tag filters, longer operators go first, so
+// they win over shorter ones at the same position.
+var tagOperators = []struct {
+	token    string
+	operator zabbix.TagOperator
+}{
+	{"!=", zabbix.TagOperatorNotEquals},
+	{"!~", zabbix.TagOperatorNotContains},
+	{"=", zabbix.TagOperatorEquals},
+	{"~", zabbix.TagOperatorContains},
+}
+
+// parseTagFilter parses --tag filter: 'key=value' (equals), 'key~value'
+// (contains), 'key!=value', 'key!~value', 'key' (exists) or '!key' (doesn't
+// exist).
+func parseTagFilter(value string) (zabbix.TagFilter, error) {
+	filter := zabbix.TagFilter{}
+
+	// the value is split by the earliest operator, so the value can contain
+	// operators, e.g. 'key~a=b'
+	first := -1
+	for _, candidate := range tagOperators {
+		index := strings.Index(value, candidate.token)
+		if index < 0 || (first >= 0 && index >= first) {
+			continue
+		}
+
+		first = index
+
+		filter.Tag = value[:index]
+		filter.Value = value[index+len(candidate.token):]
+		filter.Operator = candidate.operator
+	}
+
+	if filter.Tag == "" {
+		switch {
+		case strings.ContainsAny(value, "=~"):
+		case strings.HasPrefix(value, "!"):
+			filter.Tag = strings.TrimPrefix(value, "!")
+			filter.Operator = zabbix.TagOperatorNotExists
+		default:
+			filter.Tag = value
+			filter.Operator = zabbix.TagOperatorExists
+		}
+	}
+
+	if filter.Tag == "" {
+		return filter, fmt.Errorf(
+			"unexpected tag filter '%s', expected one of: "+
+				"key=value, key~value, key!=value, key!~value, key, !key",
+			value,
+		)
+	}
+
+	return filter, nil
+}
+
+// setTagFilters sets tags and evaltype params by --tag and --tag-eval.
+func setTagFilters(params zabbix.Params, args map[string]interface{}) error {
+	var (
+		values, _ = args["--tag"].([]string)
+		eval, _   = args["--tag-eval"].(string)
+	)
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	filters := []zabbix.TagFilter{}
+	for _, value := range values {
+		filter, err := parseTagFilter(value)
+		if err != nil {
+			return err
+		}
+
+		filters = append(filters, filter)
+	}
+
+	params["tags"] = filters
+
+	switch strings.ToLower(eval) {
+	case "", "and":
+		params["evaltype"] = zabbix.TagEvalAndOr
+	case "or":
+		params["evaltype"] = zabbix.TagEvalOr
+	default:
+		return fmt.Errorf(
+			"unexpected tag evaluation '%s', expected 'and' or 'or'", eval,
+		)
+	}
+
+	return nil
+}
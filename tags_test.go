@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/kovetskiy/godocs"
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	test := assert.New(t)
+
+	testcases := map[string]zabbix.TagFilter{
+		"service=db":   {Tag: "service", Value: "db", Operator: zabbix.TagOperatorEquals},
+		"service~d":    {Tag: "service", Value: "d", Operator: zabbix.TagOperatorContains},
+		"env!=prod":    {Tag: "env", Value: "prod", Operator: zabbix.TagOperatorNotEquals},
+		"env!~pro":     {Tag: "env", Value: "pro", Operator: zabbix.TagOperatorNotContains},
+		"team":         {Tag: "team", Operator: zabbix.TagOperatorExists},
+		"!team":        {Tag: "team", Operator: zabbix.TagOperatorNotExists},
+		"url=http://x": {Tag: "url", Value: "http://x", Operator: zabbix.TagOperatorEquals},
+		"key~a=b":      {Tag: "key", Value: "a=b", Operator: zabbix.TagOperatorContains},
+		"key=a!=b":     {Tag: "key", Value: "a!=b", Operator: zabbix.TagOperatorEquals},
+		"key!~a~b":     {Tag: "key", Value: "a~b", Operator: zabbix.TagOperatorNotContains},
+	}
+
+	for value, expected := range testcases {
+		filter, err := parseTagFilter(value)
+		test.NoError(err, value)
+		test.Equal(expected, filter, value)
+	}
+
+	for _, value := range []string{"=db", "~db", "!=db", ""} {
+		_, err := parseTagFilter(value)
+		test.Error(err, value)
+	}
+}
+
+func TestSetTagFilters(t *testing.T) {
+	test := assert.New(t)
+
+	args, err := godocs.Parse(
+		docs, version,
+		godocs.Usage(usage), godocs.Options(options), godocs.NoExit,
+		godocs.Args{"-T", "--tag", "service=db", "--tag", "!team", "--tag-eval", "or"},
+	)
+	if !test.NoError(err) {
+		return
+	}
+
+	params := zabbix.Params{}
+	test.NoError(setTagFilters(params, args))
+	test.Equal(zabbix.TagEvalOr, params["evaltype"])
+	test.Equal([]zabbix.TagFilter{
+		{Tag: "service", Value: "db", Operator: zabbix.TagOperatorEquals},
+		{Tag: "team", Operator: zabbix.TagOperatorNotExists},
+	}, params["tags"])
+
+	args["--tag-eval"] = "xor"
+	test.Error(setTagFilters(zabbix.Params{}, args))
+}
@@ -0,0 +1,608 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/nsf/termbox-go"
+)
+
+const (
+	// triageHistoryLimit is amount of history values shown for the item
+	triageHistoryLimit = 20
+
+	triageHelp = "j/k move  space select  * select all  a ack  " +
+		"enter history  / filter  r refresh  q quit"
+)
+
+type triageMode int
+
+const (
+	triageModeList triageMode = iota
+	triageModeFilter
+	triageModeMessage
+	triageModeHistory
+)
+
+// triage is the state of the interactive mode, it's updated by key events and
+// rendered into lines of text independently of the terminal.
+type triage struct {
+	ctx         context.Context
+	servers     []Server
+	params      zabbix.Params
+	filters     hostFilters
+	order       string
+	allContexts bool
+
+	rows     []triggerRow
+	shown    []triggerRow
+	selected map[string]bool
+	cursor   int
+	offset   int
+
+	mode    triageMode
+	filter  string
+	input   string
+	status  string
+	history []string
+
+	// excludes are patterns of !/<pattern> arguments and --exclude, they
+	// are applied along with the filter.
+	excludes []string
+}
+
+func newTriage(
+	ctx context.Context,
+	servers []Server,
+	params zabbix.Params,
+	filters hostFilters,
+	order string,
+	allContexts bool,
+) *triage {
+	return &triage{
+		ctx:         ctx,
+		servers:     servers,
+		params:      params,
+		filters:     filters,
+		order:       order,
+		allContexts: allContexts,
+		selected:    map[string]bool{},
+	}
+}
+
+// refresh retrieves triggers of every server, servers which can't be reached
+// are reported in the status line by reportSkipped. The previous triggers are
+// kept and the error is reported when none of servers can be reached.
+func (triage *triage) refresh() error {
+	triage.status = ""
+
+	results, err := getServersTriggers(
+		triage.ctx, triage.servers, triage.params, triage.filters, false,
+	)
+	if err != nil {
+		triage.report("can't refresh triggers: %s", err)
+		return err
+	}
+
+	triage.rows = getTriggerRows(results, triage.order)
+
+	// selection of events which aren't listed anymore is dropped
+	listed := map[string]bool{}
+	for _, row := range triage.rows {
+		listed[problemKey(row)] = true
+	}
+
+	for key := range triage.selected {
+		if !listed[key] {
+			delete(triage.selected, key)
+		}
+	}
+
+	triage.applyFilter()
+
+	return nil
+}
+
+// report adds the message to the status line.
+func (triage *triage) report(format string, values ...interface{}) {
+	if triage.status != "" {
+		triage.status += "; "
+	}
+
+	triage.status += fmt.Sprintf(format, values...)
+}
+
+// applyFilter lists rows matching the filter using the same search mode as
+// the /<pattern> argument.
+func (triage *triage) applyFilter() {
+	query := searchQuery{
+		pattern:  getSearchPattern([]string{triage.filter}),
+		excludes: triage.excludes,
+	}
+
+	triage.shown = []triggerRow{}
+	for _, row := range triage.rows {
+		if !query.match(row.trigger.String()) {
+			continue
+		}
+
+		triage.shown = append(triage.shown, row)
+	}
+
+	triage.move(0)
+}
+
+// move moves the cursor by the delta within listed rows.
+func (triage *triage) move(delta int) {
+	triage.cursor += delta
+
+	if triage.cursor >= len(triage.shown) {
+		triage.cursor = len(triage.shown) - 1
+	}
+
+	if triage.cursor < 0 {
+		triage.cursor = 0
+	}
+}
+
+// current returns the row under the cursor, ok is false when nothing is
+// listed.
+func (triage *triage) current() (row triggerRow, ok bool) {
+	if len(triage.shown) == 0 {
+		return row, false
+	}
+
+	return triage.shown[triage.cursor], true
+}
+
+func (triage *triage) toggle() {
+	row, ok := triage.current()
+	if !ok {
+		return
+	}
+
+	if triage.selected[problemKey(row)] {
+		delete(triage.selected, problemKey(row))
+	} else {
+		triage.selected[problemKey(row)] = true
+	}
+
+	triage.move(1)
+}
+
+// toggleAll selects every listed row or clears the selection when every
+// listed row is already selected.
+func (triage *triage) toggleAll() {
+	all := true
+	for _, row := range triage.shown {
+		if !triage.selected[problemKey(row)] {
+			all = false
+			break
+		}
+	}
+
+	for _, row := range triage.shown {
+		if all {
+			delete(triage.selected, problemKey(row))
+		} else {
+			triage.selected[problemKey(row)] = true
+		}
+	}
+}
+
+// targets returns selected rows or the row under the cursor when nothing is
+// selected.
+func (triage *triage) targets() []triggerRow {
+	targets := []triggerRow{}
+	for _, row := range triage.rows {
+		if triage.selected[problemKey(row)] {
+			targets = append(targets, row)
+		}
+	}
+
+	if len(targets) == 0 {
+		if row, ok := triage.current(); ok {
+			targets = append(targets, row)
+		}
+	}
+
+	return targets
+}
+
+// acknowledge acknowledges events of target rows with the message and
+// refreshes the listing.
+func (triage *triage) acknowledge(message string) {
+	targets := triage.targets()
+	if len(targets) == 0 {
+		return
+	}
+
+	var (
+		servers     = []Server{}
+		identifiers = map[string][]string{}
+	)
+
+	for _, row := range targets {
+		if _, ok := identifiers[row.Name]; !ok {
+			servers = append(servers, row.Server)
+		}
+
+		identifiers[row.Name] = append(
+			identifiers[row.Name], row.trigger.LastEvent.ID,
+		)
+	}
+
+	options := zabbix.AcknowledgeOptions{Acknowledge: true, Message: message}
+
+	for _, server := range servers {
+		err := server.Client.Acknowledge(
+			triage.ctx, identifiers[server.Name], options,
+		)
+		if err != nil {
+			triage.status = fmt.Sprintf(
+				"can't acknowledge events %s: %s", identifiers[server.Name], err,
+			)
+
+			return
+		}
+	}
+
+	triage.selected = map[string]bool{}
+	triage.refresh()
+
+	triage.status = fmt.Sprintf("%d events acknowledged", len(targets))
+}
+
+// openHistory shows the latest value and history of the item from the first
+// function of the trigger expression under the cursor.
+func (triage *triage) openHistory() {
+	row, ok := triage.current()
+	if !ok {
+		return
+	}
+
+	if len(row.trigger.Functions) == 0 {
+		triage.status = "the trigger has no items"
+		return
+	}
+
+	items, err := row.Client.GetItems(triage.ctx, zabbix.Params{
+		"itemids":  row.trigger.Functions[0].ItemID,
+		"output":   "extend",
+		"webitems": true,
+	})
+	if err != nil || len(items) == 0 {
+		triage.status = fmt.Sprintf("can't obtain the item: %v", err)
+		return
+	}
+
+	item := items[0]
+
+	history, err := row.Client.GetHistory(triage.ctx, zabbix.Params{
+		"itemids": item.ID,
+		"history": item.ValueType,
+		"limit":   triageHistoryLimit,
+	})
+	if err != nil {
+		triage.status = fmt.Sprintf("can't obtain history of the item: %s", err)
+		return
+	}
+
+	triage.history = []string{
+		fmt.Sprintf("%s: %s", row.trigger.GetHostName(), row.trigger.Description),
+		fmt.Sprintf("%s (%s)", item.Format(), item.Key),
+		fmt.Sprintf("last value: %s at %s", item.LastValue, item.DateTime()),
+		"",
+	}
+
+	for _, value := range history {
+		triage.history = append(
+			triage.history,
+			fmt.Sprintf("%s  %s", value.DateTime(), value.String()),
+		)
+	}
+
+	triage.mode = triageModeHistory
+}
+
+// handle updates the state by the key event, quit is true when the user asks
+// to leave the interactive mode.
+func (triage *triage) handle(event termbox.Event) (quit bool) {
+	switch triage.mode {
+	case triageModeFilter, triageModeMessage:
+		triage.handleInput(event)
+
+		return false
+
+	case triageModeHistory:
+		switch {
+		case event.Key == termbox.KeyEsc, event.Key == termbox.KeyEnter,
+			event.Ch == 'q':
+			triage.mode = triageModeList
+		}
+
+		return false
+	}
+
+	switch {
+	case event.Key == termbox.KeyCtrlC, event.Ch == 'q':
+		return true
+
+	case event.Key == termbox.KeyArrowUp, event.Ch == 'k':
+		triage.move(-1)
+
+	case event.Key == termbox.KeyArrowDown, event.Ch == 'j':
+		triage.move(1)
+
+	case event.Key == termbox.KeyPgup:
+		triage.move(-10)
+
+	case event.Key == termbox.KeyPgdn:
+		triage.move(10)
+
+	case event.Key == termbox.KeyHome, event.Ch == 'g':
+		triage.move(-len(triage.shown))
+
+	case event.Key == termbox.KeyEnd, event.Ch == 'G':
+		triage.move(len(triage.shown))
+
+	case event.Key == termbox.KeySpace:
+		triage.toggle()
+
+	case event.Ch == '*':
+		triage.toggleAll()
+
+	case event.Key == termbox.KeyEnter, event.Ch == 'v':
+		triage.openHistory()
+
+	case event.Ch == 'a':
+		if len(triage.targets()) > 0 {
+			triage.mode = triageModeMessage
+			triage.input = ""
+		}
+
+	case event.Ch == '/':
+		triage.mode = triageModeFilter
+		triage.input = triage.filter
+
+	case event.Ch == 'r':
+		triage.refresh()
+
+	case event.Key == termbox.KeyEsc:
+		triage.filter = ""
+		triage.applyFilter()
+	}
+
+	return false
+}
+
+// handleInput edits the filter or the message, the filter is applied while
+// it's typed.
+func (triage *triage) handleInput(event termbox.Event) {
+	switch {
+	case event.Key == termbox.KeyEsc, event.Key == termbox.KeyCtrlC:
+		if triage.mode == triageModeFilter {
+			triage.filter = ""
+			triage.applyFilter()
+		}
+
+		triage.mode = triageModeList
+
+		return
+
+	case event.Key == termbox.KeyEnter:
+		if triage.mode == triageModeMessage {
+			triage.acknowledge(strings.TrimSpace(triage.input))
+		}
+
+		triage.mode = triageModeList
+
+		return
+
+	case event.Key == termbox.KeyBackspace, event.Key == termbox.KeyBackspace2:
+		runes := []rune(triage.input)
+		if len(runes) > 0 {
+			triage.input = string(runes[:len(runes)-1])
+		}
+
+	case event.Key == termbox.KeySpace:
+		triage.input += " "
+
+	case event.Ch != 0:
+		triage.input += string(event.Ch)
+
+	default:
+		return
+	}
+
+	if triage.mode == triageModeFilter {
+		triage.filter = triage.input
+		triage.applyFilter()
+	}
+}
+
+// view returns lines of the screen of the given height and the index of the
+// highlighted line, -1 when no line is highlighted.
+func (triage *triage) view(height int) (lines []string, highlighted int) {
+	if triage.mode == triageModeHistory {
+		lines = append(lines, triage.history...)
+		lines = append(lines, "", "esc back")
+
+		return lines, -1
+	}
+
+	header := fmt.Sprintf(
+		"%d triggers, %d selected", len(triage.shown), len(triage.selected),
+	)
+	if triage.filter != "" {
+		header += ", filter: " + triage.filter
+	}
+
+	// header, blank line and footer
+	size := height - 3
+	if size < 1 {
+		size = 1
+	}
+
+	if triage.cursor < triage.offset {
+		triage.offset = triage.cursor
+	}
+
+	if triage.cursor >= triage.offset+size {
+		triage.offset = triage.cursor - size + 1
+	}
+
+	var (
+		buffer bytes.Buffer
+		table  = tabwriter.NewWriter(&buffer, 1, 4, 2, ' ', 0)
+	)
+
+	for _, row := range triage.shown {
+		mark := " "
+		if triage.selected[problemKey(row)] {
+			mark = "*"
+		}
+
+		fmt.Fprint(table, mark+"\t")
+
+		writeTriggerRow(table, row, ExtendedOutputNone, triage.allContexts)
+	}
+
+	table.Flush()
+
+	rows := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(triage.shown) == 0 {
+		rows = []string{}
+	}
+
+	end := triage.offset + size
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	lines = append(lines, header, "")
+	lines = append(lines, rows[triage.offset:end]...)
+
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	switch {
+	case triage.mode == triageModeFilter:
+		lines = append(lines, "/"+triage.input)
+	case triage.mode == triageModeMessage:
+		lines = append(
+			lines,
+			fmt.Sprintf(
+				"acknowledge %d events, message: %s",
+				len(triage.targets()), triage.input,
+			),
+		)
+	case triage.status != "":
+		lines = append(lines, triage.status)
+	default:
+		lines = append(lines, triageHelp)
+	}
+
+	highlighted = -1
+	if len(triage.shown) > 0 {
+		highlighted = 2 + triage.cursor - triage.offset
+	}
+
+	return lines, highlighted
+}
+
+// handleTriage lists triggers in the interactive mode.
+func handleTriage(
+	ctx context.Context,
+	servers []Server,
+	params zabbix.Params,
+	filters hostFilters,
+	args map[string]interface{},
+) error {
+	var (
+		_, query    = parseSearchQuery(args)
+		allContexts = args["--all-contexts"].(bool)
+		order       = args["--order"].(string)
+	)
+
+	triage := newTriage(ctx, servers, params, filters, order, allContexts)
+	triage.filter = query.text
+	triage.excludes = query.excludes
+
+	// spinners and warnings would break the screen
+	quietMode = true
+
+	reportSkipped = func(server Server, reason string, err error) {
+		debugf("%s, context %s is skipped: %s", reason, server.Name, err)
+		triage.report("%s, context %s is skipped", reason, server.Name)
+	}
+
+	err := triage.refresh()
+	if err != nil {
+		return err
+	}
+
+	return runTriage(triage)
+}
+
+// runTriage runs the interactive mode in the terminal until the user quits.
+func runTriage(triage *triage) error {
+	err := termbox.Init()
+	if err != nil {
+		return err
+	}
+
+	defer termbox.Close()
+
+	for {
+		drawTriage(triage)
+
+		event := termbox.PollEvent()
+		switch event.Type {
+		case termbox.EventError:
+			return event.Err
+
+		case termbox.EventKey:
+			if triage.handle(event) {
+				return nil
+			}
+		}
+	}
+}
+
+func drawTriage(triage *triage) {
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+
+	width, height := termbox.Size()
+
+	lines, highlighted := triage.view(height)
+	for y, line := range lines {
+		foreground, background := termbox.ColorDefault, termbox.ColorDefault
+		if y == highlighted {
+			foreground |= termbox.AttrReverse
+		}
+
+		x := 0
+		for _, char := range line {
+			if x >= width {
+				break
+			}
+
+			termbox.SetCell(x, y, char, foreground, background)
+			x++
+		}
+
+		if y == highlighted {
+			for ; x < width; x++ {
+				termbox.SetCell(x, y, ' ', foreground, background)
+			}
+		}
+	}
+
+	termbox.Flush()
+}
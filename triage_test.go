@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/nsf/termbox-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// triageTestServer is a zabbix server listing triggers 1, 2 and 3 with
+// events 100, 200 and 300, acknowledged events are listed as acknowledged
+// afterwards.
+type triageTestServer struct {
+	*httptest.Server
+
+	acknowledged []string
+	messages     []string
+}
+
+func newTriageTestServer(t *testing.T) *triageTestServer {
+	server := &triageTestServer{}
+
+	triggers := []map[string]interface{}{
+		newTriageTrigger("1", "100", "db1", "Replication lag"),
+		newTriageTrigger("2", "200", "web1", "Disk full"),
+		newTriageTrigger("3", "300", "db2", "Replication stopped"),
+	}
+
+	result := func(request testRequest) interface{} {
+		switch request.Method {
+		case "apiinfo.version":
+			return "6.0.0"
+
+		case "trigger.get":
+			result := map[string]interface{}{}
+			for _, trigger := range triggers {
+				event := trigger["lastEvent"].(map[string]interface{})
+
+				acknowledged := "0"
+				for _, identifier := range server.acknowledged {
+					if identifier == event["eventid"] {
+						acknowledged = "1"
+					}
+				}
+
+				event["acknowledged"] = acknowledged
+				result[trigger["triggerid"].(string)] = trigger
+			}
+
+			return result
+
+		case "event.acknowledge":
+			for _, identifier := range request.Params["eventids"].([]interface{}) {
+				server.acknowledged = append(
+					server.acknowledged, identifier.(string),
+				)
+			}
+
+			message, _ := request.Params["message"].(string)
+			server.messages = append(server.messages, message)
+
+			return map[string]interface{}{
+				"eventids": request.Params["eventids"],
+			}
+
+		case "item.get":
+			return []interface{}{map[string]interface{}{
+				"itemid":     request.Params["itemids"],
+				"name":       "CPU load",
+				"key_":       "system.cpu.load",
+				"value_type": "0",
+				"lastvalue":  "3.5",
+				"lastclock":  "1700000000",
+			}}
+
+		case "history.get":
+			return []interface{}{
+				map[string]interface{}{
+					"itemid": request.Params["itemids"],
+					"value":  "3.5",
+					"clock":  "1700000000",
+				},
+				map[string]interface{}{
+					"itemid": request.Params["itemids"],
+					"value":  "1.2",
+					"clock":  "1699999940",
+				},
+			}
+		}
+
+		t.Errorf("unexpected method %s", request.Method)
+
+		return nil
+	}
+
+	server.Server = newTestServer(result)
+
+	return server
+}
+
+func newTriageTrigger(id, event, host, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"triggerid":   id,
+		"description": description,
+		"host":        host,
+		"value":       "1",
+		"priority":    "4",
+		"lastchange":  "1700000000",
+		"functions":   []interface{}{map[string]interface{}{"itemid": "1" + id}},
+		"hosts":       []interface{}{map[string]interface{}{"name": host}},
+		"lastEvent":   map[string]interface{}{"eventid": event},
+	}
+}
+
+func newTestTriage(t *testing.T) (*triage, *triageTestServer) {
+	server := newTriageTestServer(t)
+	t.Cleanup(server.Close)
+
+	client, err := zabbix.NewZabbix(context.Background(), zabbix.Options{
+		Address: server.URL,
+		Token:   "token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quietMode = true
+	t.Cleanup(func() { quietMode = false })
+
+	triage := newTriage(
+		context.Background(),
+		[]Server{{Name: "prod", Client: client}},
+		zabbix.Params{},
+		hostFilters{},
+		"DESC",
+		false,
+	)
+
+	err = triage.refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return triage, server
+}
+
+func typeKeys(triage *triage, text string) {
+	for _, char := range text {
+		triage.handle(termbox.Event{Type: termbox.EventKey, Ch: char})
+	}
+}
+
+func pressKey(triage *triage, key termbox.Key) bool {
+	return triage.handle(termbox.Event{Type: termbox.EventKey, Key: key})
+}
+
+// moveTo moves the cursor to the row of the event by keys, triggers of a
+// single server are listed in the order of the response.
+func moveTo(triage *triage, event string) {
+	typeKeys(triage, "g")
+
+	for _, row := range triage.shown {
+		if row.trigger.LastEvent.ID == event {
+			return
+		}
+
+		typeKeys(triage, "j")
+	}
+}
+
+func TestTriageMove(t *testing.T) {
+	test := assert.New(t)
+
+	triage, _ := newTestTriage(t)
+
+	test.Len(triage.shown, 3)
+	test.Equal(0, triage.cursor)
+
+	typeKeys(triage, "jj")
+	test.Equal(2, triage.cursor)
+
+	pressKey(triage, termbox.KeyArrowDown)
+	test.Equal(2, triage.cursor, "the cursor stays on the last row")
+
+	typeKeys(triage, "g")
+	test.Equal(0, triage.cursor)
+
+	pressKey(triage, termbox.KeyArrowUp)
+	test.Equal(0, triage.cursor)
+
+	typeKeys(triage, "G")
+	test.Equal(2, triage.cursor)
+
+	lines, highlighted := triage.view(10)
+	test.Len(lines, 10)
+	test.Equal(4, highlighted)
+	test.Contains(lines[highlighted], triage.shown[2].trigger.Description)
+	test.Equal(triageHelp, lines[9])
+
+	test.True(pressKey(triage, termbox.KeyCtrlC))
+}
+
+func TestTriageAcknowledgeSelected(t *testing.T) {
+	test := assert.New(t)
+
+	triage, server := newTestTriage(t)
+
+	moveTo(triage, "100")
+	pressKey(triage, termbox.KeySpace)
+	moveTo(triage, "300")
+	pressKey(triage, termbox.KeySpace)
+
+	test.Equal(map[string]bool{"prod/1/100": true, "prod/3/300": true}, triage.selected)
+
+	lines, _ := triage.view(10)
+	for index, row := range triage.shown {
+		mark := " "
+		if row.trigger.LastEvent.ID != "200" {
+			mark = "*"
+		}
+
+		test.True(strings.HasPrefix(lines[2+index], mark), lines[2+index])
+	}
+
+	typeKeys(triage, "a")
+	test.Equal(triageModeMessage, triage.mode)
+
+	typeKeys(triage, "on")
+	pressKey(triage, termbox.KeySpace)
+	typeKeys(triage, "itx")
+	pressKey(triage, termbox.KeyBackspace2)
+
+	lines, _ = triage.view(10)
+	test.Equal("acknowledge 2 events, message: on it", lines[9])
+
+	pressKey(triage, termbox.KeyEnter)
+
+	test.Equal(triageModeList, triage.mode)
+	test.ElementsMatch([]string{"100", "300"}, server.acknowledged)
+	test.Equal([]string{"on it"}, server.messages)
+	test.Empty(triage.selected)
+	test.Equal("2 events acknowledged", triage.status)
+
+	for _, row := range triage.shown {
+		status := "ACK"
+		if row.trigger.LastEvent.ID == "200" {
+			status = "NACK"
+		}
+
+		test.Equal(status, row.trigger.StatusAcknowledge())
+	}
+}
+
+func TestTriageAcknowledgeCancel(t *testing.T) {
+	test := assert.New(t)
+
+	triage, server := newTestTriage(t)
+
+	typeKeys(triage, "ajunk")
+	pressKey(triage, termbox.KeyEsc)
+
+	test.Equal(triageModeList, triage.mode)
+	test.Empty(server.acknowledged)
+
+	moveTo(triage, "200")
+	typeKeys(triage, "a")
+	pressKey(triage, termbox.KeyEnter)
+
+	test.Equal([]string{"200"}, server.acknowledged, "the current row is acknowledged")
+	test.Equal([]string{""}, server.messages)
+}
+
+func TestTriageFilter(t *testing.T) {
+	test := assert.New(t)
+
+	triage, _ := newTestTriage(t)
+
+	typeKeys(triage, "/dbrpl")
+	test.Equal(triageModeFilter, triage.mode)
+	test.Len(triage.shown, 2, "the filter is applied while typing")
+
+	typeKeys(triage, "s")
+	test.Len(triage.shown, 1)
+
+	pressKey(triage, termbox.KeyBackspace2)
+	pressKey(triage, termbox.KeyEnter)
+	test.Equal(triageModeList, triage.mode)
+	test.Equal("dbrpl", triage.filter)
+	test.Len(triage.shown, 2)
+
+	lines, _ := triage.view(10)
+	test.Contains(lines[0], "2 triggers, 0 selected, filter: dbrpl")
+
+	// select all listed
+	typeKeys(triage, "*")
+	test.Len(triage.selected, 2)
+
+	typeKeys(triage, "*")
+	test.Empty(triage.selected)
+
+	typeKeys(triage, "/")
+	pressKey(triage, termbox.KeyEsc)
+	test.Equal("", triage.filter)
+	test.Len(triage.shown, 3)
+}
+
+func TestTriageHistory(t *testing.T) {
+	test := assert.New(t)
+
+	triage, _ := newTestTriage(t)
+
+	moveTo(triage, "200")
+	pressKey(triage, termbox.KeyEnter)
+
+	test.Equal(triageModeHistory, triage.mode)
+
+	lines, highlighted := triage.view(10)
+	test.Equal(-1, highlighted)
+	test.Equal("web1: Disk full", lines[0])
+	test.Equal("CPU load (system.cpu.load)", lines[1])
+	test.Contains(lines[2], "last value: 3.5")
+	test.Contains(lines[4], "3.5")
+	test.Contains(lines[5], "1.2")
+
+	typeKeys(triage, "q")
+	test.Equal(triageModeList, triage.mode, "q leaves history only")
+}
+
+func TestTriageRefreshError(t *testing.T) {
+	test := assert.New(t)
+
+	triage, server := newTestTriage(t)
+
+	pressKey(triage, termbox.KeySpace)
+
+	server.Close()
+	typeKeys(triage, "r")
+
+	test.Len(triage.shown, 3, "the previous triggers are kept")
+	test.Len(triage.selected, 1)
+	test.True(strings.HasPrefix(triage.status, "can't refresh triggers: "))
+
+	lines, highlighted := triage.view(6)
+	test.Equal(3, highlighted)
+	test.Equal(triage.status, lines[5])
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/reconquest/karma-go"
+)
+
+// serverTriggersStatus are triggers of a single server which status is
+// changed.
+type serverTriggersStatus struct {
+	Server
+
+	triggers []zabbix.Trigger
+}
+
+// parseTriggersStatus returns the status requested by --enable or --disable,
+// ok is false when the status isn't changed.
+func parseTriggersStatus(
+	args map[string]interface{},
+) (status string, ok bool, err error) {
+	var (
+		enable, _  = args["--enable"].(bool)
+		disable, _ = args["--disable"].(bool)
+	)
+
+	switch {
+	case enable && disable:
+		return "", false, errors.New(
+			"--enable and --disable are mutually exclusive",
+		)
+	case enable:
+		return zabbix.TriggerStatusEnabled, true, nil
+	case disable:
+		return zabbix.TriggerStatusDisabled, true, nil
+	}
+
+	return "", false, nil
+}
+
+// describeTriggersStatus returns the action of the status for the
+// confirmation and the summary.
+func describeTriggersStatus(status string) string {
+	if status == zabbix.TriggerStatusEnabled {
+		return "enable"
+	}
+
+	return "disable"
+}
+
+// updateTriggersStatus enables or disables triggers of every server after the
+// confirmation and prints the summary of changed triggers.
+func updateTriggersStatus(
+	ctx context.Context,
+	servers []serverTriggersStatus,
+	status string,
+	confirmation bool,
+	allContexts bool,
+) error {
+	amount := 0
+	for _, server := range servers {
+		amount += len(server.triggers)
+	}
+
+	if amount == 0 {
+		return nil
+	}
+
+	action := describeTriggersStatus(status)
+
+	if confirmation {
+		if !confirmAcknowledge(fmt.Sprintf("%s %d triggers", action, amount)) {
+			return nil
+		}
+	}
+
+	changed := []serverTriggersStatus{}
+	for _, server := range servers {
+		identifiers := []string{}
+		for _, trigger := range server.triggers {
+			identifiers = append(identifiers, trigger.ID)
+		}
+
+		err := withSpinner(
+			":: Updating status of specified triggers",
+			func() error {
+				return server.Client.SetTriggersStatus(ctx, identifiers, status)
+			},
+		)
+		if err != nil {
+			// triggers of previous servers are changed already
+			writeTriggersStatusSummary(os.Stdout, changed, action, allContexts)
+
+			return karma.Format(
+				err,
+				"can't %s triggers %s",
+				action, identifiers,
+			)
+		}
+
+		changed = append(changed, server)
+	}
+
+	return writeTriggersStatusSummary(os.Stdout, changed, action, allContexts)
+}
+
+// writeTriggersStatusSummary writes triggers which status is changed.
+func writeTriggersStatusSummary(
+	writer io.Writer,
+	servers []serverTriggersStatus,
+	action string,
+	allContexts bool,
+) error {
+	amount := 0
+	for _, server := range servers {
+		amount += len(server.triggers)
+	}
+
+	if amount == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(writer, "\n:: %d triggers are %sd:\n", amount, action)
+
+	table := tabwriter.NewWriter(writer, 1, 4, 2, ' ', 0)
+
+	for _, server := range servers {
+		for _, trigger := range server.triggers {
+			var prefix string
+			if allContexts {
+				prefix = server.Name + "\t"
+			}
+
+			fmt.Fprintf(
+				table,
+				"%s%s\t%s\t%s\t%s\n",
+				prefix,
+				trigger.ID, trigger.Severity(),
+				trigger.GetHostName(), trigger.Description,
+			)
+		}
+	}
+
+	return table.Flush()
+}
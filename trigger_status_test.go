@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTriggersStatus(t *testing.T) {
+	test := assert.New(t)
+
+	status, ok, err := parseTriggersStatus(map[string]interface{}{
+		"--enable":  false,
+		"--disable": true,
+	})
+	test.NoError(err)
+	test.True(ok)
+	test.Equal(zabbix.TriggerStatusDisabled, status)
+
+	status, ok, err = parseTriggersStatus(map[string]interface{}{
+		"--enable":  true,
+		"--disable": false,
+	})
+	test.NoError(err)
+	test.True(ok)
+	test.Equal(zabbix.TriggerStatusEnabled, status)
+
+	_, ok, err = parseTriggersStatus(map[string]interface{}{
+		"--enable":  false,
+		"--disable": false,
+	})
+	test.NoError(err)
+	test.False(ok)
+
+	_, _, err = parseTriggersStatus(map[string]interface{}{
+		"--enable":  true,
+		"--disable": true,
+	})
+	test.Error(err)
+}
+
+func TestWriteTriggersStatusSummary(t *testing.T) {
+	test := assert.New(t)
+
+	trigger := zabbix.Trigger{
+		ID:          "100",
+		Description: "High CPU",
+		Priority:    "4",
+	}
+	trigger.Hosts = append(trigger.Hosts, struct {
+		Hostid string `json:"hostid"`
+		Name   string `json:"name"`
+	}{"1", "db1"})
+
+	var buffer bytes.Buffer
+
+	err := writeTriggersStatusSummary(
+		&buffer,
+		[]serverTriggersStatus{
+			{Server: Server{Name: "prod"}, triggers: []zabbix.Trigger{trigger}},
+		},
+		"disable",
+		true,
+	)
+	test.NoError(err)
+	test.Equal(
+		"\n:: 1 triggers are disabled:\nprod  100  HIGH  db1  High CPU\n",
+		buffer.String(),
+	)
+
+	buffer.Reset()
+
+	err = writeTriggersStatusSummary(&buffer, nil, "enable", false)
+	test.NoError(err)
+	test.Empty(buffer.String())
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+)
+
+const (
+	// watchClear moves the cursor home and clears the screen
+	watchClear = "\033[H\033[2J"
+
+	watchHighlight = "\033[1;7m"
+	watchReset     = "\033[0m"
+)
+
+// triggersWatch keeps triggers of the previous refresh of --watch for finding
+// new and resolved problems.
+type triggersWatch struct {
+	// problems are triggers in a problem state by their server and last event,
+	// nil before the first refresh
+	problems map[string]triggerRow
+
+	rows     []triggerRow
+	fresh    map[string]bool
+	resolved []triggerRow
+	updated  time.Time
+	status   string
+}
+
+// problemKey identifies the problem of the trigger on its server.
+func problemKey(row triggerRow) string {
+	return row.Name + "/" + row.trigger.ID + "/" + row.trigger.LastEvent.ID
+}
+
+// update replaces triggers of the watch, problems which weren't listed by the
+// previous refresh become fresh, the listed ones which are not in a problem
+// state anymore become resolved. Problems of servers which weren't refreshed
+// are kept.
+func (watch *triggersWatch) update(
+	rows []triggerRow,
+	refreshed map[string]bool,
+	updated time.Time,
+) {
+	var (
+		problems = map[string]triggerRow{}
+		current  = map[string]bool{}
+	)
+
+	watch.fresh = map[string]bool{}
+	watch.resolved = []triggerRow{}
+
+	for _, row := range rows {
+		if row.trigger.Value != "1" {
+			continue
+		}
+
+		key := problemKey(row)
+
+		problems[key] = row
+		current[key] = true
+
+		if _, ok := watch.problems[key]; !ok && watch.problems != nil {
+			watch.fresh[key] = true
+		}
+	}
+
+	for key, row := range watch.problems {
+		switch {
+		case current[key]:
+		case !refreshed[row.Name]:
+			problems[key] = row
+		default:
+			watch.resolved = append(watch.resolved, row)
+		}
+	}
+
+	sortTriggersByLastChange(watch.resolved, "DESC")
+
+	watch.problems = problems
+	watch.rows = rows
+	watch.updated = updated
+}
+
+// summary returns amounts of triggers in a problem state by severity.
+func (watch *triggersWatch) summary() string {
+	amounts := map[zabbix.Severity]int{}
+	for _, row := range watch.problems {
+		amounts[row.trigger.Severity()]++
+	}
+
+	counters := []string{}
+	for severity := zabbix.SeverityDisaster; severity >= zabbix.SeverityNotClassified; severity-- {
+		if severity == zabbix.SeverityNotClassified && amounts[severity] == 0 {
+			continue
+		}
+
+		counters = append(
+			counters,
+			fmt.Sprintf("%s: %d", severity, amounts[severity]),
+		)
+	}
+
+	return strings.Join(counters, "  ")
+}
+
+// draw writes the screen with the summary header, triggers where fresh
+// problems are highlighted and triggers resolved since the previous refresh.
+func (watch *triggersWatch) draw(
+	writer io.Writer,
+	interval time.Duration,
+	extended ExtendedOutput,
+	allContexts bool,
+) {
+	fmt.Fprint(writer, watchClear)
+
+	fmt.Fprintf(
+		writer,
+		"Every %s, updated %s    %s\n\n",
+		interval, watch.updated.Format(zabbix.TimeFormat), watch.summary(),
+	)
+
+	var (
+		buffer bytes.Buffer
+		table  = tabwriter.NewWriter(&buffer, 1, 4, 2, ' ', 0)
+	)
+
+	for _, row := range watch.rows {
+		writeTriggerRow(table, row, extended, allContexts)
+	}
+
+	table.Flush()
+
+	// every trigger takes a single line of the table
+	lines := strings.SplitAfter(buffer.String(), "\n")
+	for index, row := range watch.rows {
+		if watch.fresh[problemKey(row)] {
+			fmt.Fprint(
+				writer,
+				watchHighlight+strings.TrimSuffix(lines[index], "\n")+
+					watchReset+"\n",
+			)
+
+			continue
+		}
+
+		fmt.Fprint(writer, lines[index])
+	}
+
+	if len(watch.resolved) > 0 {
+		fmt.Fprintln(writer, "\nResolved since the previous refresh:")
+
+		buffer.Reset()
+		for _, row := range watch.resolved {
+			writeTriggerRow(table, row, ExtendedOutputNone, allContexts)
+		}
+
+		table.Flush()
+
+		fmt.Fprint(writer, buffer.String())
+	}
+
+	if watch.status != "" {
+		fmt.Fprintf(writer, "\n%s\n", watch.status)
+	}
+
+	fmt.Fprint(
+		writer,
+		"\n:: Type an event ID and optionally a message to acknowledge it: ",
+	)
+}
+
+// report adds the line to the status shown by the next draw.
+func (watch *triggersWatch) report(format string, values ...interface{}) {
+	if watch.status != "" {
+		watch.status += "\n"
+	}
+
+	watch.status += fmt.Sprintf(format, values...)
+}
+
+// acknowledge acknowledges the listed event given by the line of input as
+// '<event> [<message>]'.
+func (watch *triggersWatch) acknowledge(ctx context.Context, line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+
+	var (
+		identifier = fields[0]
+		options    = zabbix.AcknowledgeOptions{
+			Acknowledge: true,
+			Message:     strings.TrimSpace(strings.TrimPrefix(line, identifier)),
+		}
+		found bool
+	)
+
+	for _, row := range watch.rows {
+		if row.trigger.LastEvent.ID != identifier {
+			continue
+		}
+
+		found = true
+
+		err := row.Client.Acknowledge(ctx, []string{identifier}, options)
+		if err != nil {
+			watch.report(":: Can't acknowledge event %s: %s", identifier, err)
+			return
+		}
+	}
+
+	if !found {
+		watch.report(":: Event %s isn't listed", identifier)
+		return
+	}
+
+	watch.report(":: Event %s acknowledged", identifier)
+}
+
+// watchTriggers re-runs the triggers query every interval and redraws the
+// screen until the context is done, events are acknowledged by lines of
+// stdin between refreshes. Configured hooks are run in the background for
+// new and resolved problems.
+func watchTriggers(
+	ctx context.Context,
+	servers []Server,
+	params zabbix.Params,
+	filters hostFilters,
+	interval time.Duration,
+	config *Config,
+	args map[string]interface{},
+) error {
+	var (
+		_, query    = parseSearchQuery(args)
+		extended    = ExtendedOutput(args["--extended"].(int))
+		allContexts = args["--all-contexts"].(bool)
+		order       = args["--order"].(string)
+
+		watch = &triggersWatch{}
+		input = make(chan string)
+
+		// notified receives the result of hooks, nil while hooks aren't run
+		notified chan error
+	)
+
+	hooks, err := newWatchHooks(config)
+	if err != nil {
+		return err
+	}
+
+	// spinners and warnings would break the redrawn screen
+	quietMode = true
+
+	reportSkipped = func(server Server, reason string, err error) {
+		debugf("%s, context %s is skipped: %s", reason, server.Name, err)
+		watch.report(":: %s, context %s is skipped", reason, server.Name)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			input <- scanner.Text()
+		}
+
+		close(input)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		results, err := getServersTriggers(
+			ctx, servers, params, filters, extended != ExtendedOutputNone,
+		)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil && watch.problems == nil:
+			return err
+		case err != nil:
+			// the previous triggers are kept until the next refresh
+			watch.report(":: Can't refresh triggers: %s", err)
+		default:
+			rows := []triggerRow{}
+			for _, row := range getTriggerRows(results, order) {
+				if !query.match(row.trigger.String()) {
+					continue
+				}
+
+				rows = append(rows, row)
+			}
+
+			refreshed := map[string]bool{}
+			clients := map[string]hooksClient{}
+			for _, result := range results {
+				refreshed[result.Name] = true
+				clients[result.Name] = result.Client
+			}
+
+			watch.update(rows, refreshed, time.Now())
+
+			// slow hooks must not block the screen and acknowledgements,
+			// problems are notified by the next refresh while hooks run
+			if hooks != nil && notified == nil {
+				notified = make(chan error, 1)
+
+				go func() {
+					notified <- hooks.notify(ctx, rows, clients)
+				}()
+			}
+		}
+
+		watch.draw(os.Stdout, interval, extended, allContexts)
+
+		watch.status = ""
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				fmt.Println()
+				return nil
+
+			case <-ticker.C:
+				break wait
+
+			case err := <-notified:
+				notified = nil
+
+				// the failure is shown by the next draw
+				if err != nil {
+					watch.report(":: Hooks failed: %s", err)
+				}
+
+			case line, ok := <-input:
+				if !ok {
+					// stdin is closed, keep refreshing only
+					input = nil
+					continue
+				}
+
+				watch.acknowledge(ctx, line)
+
+				break wait
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lasseoe/zabbixctl/zabbix"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWatchRow(server *serverTriggers, id, event, value, priority string) triggerRow {
+	trigger := zabbix.Trigger{
+		ID:       id,
+		Value:    value,
+		Priority: priority,
+	}
+	trigger.LastEvent.ID = event
+
+	return triggerRow{serverTriggers: server, trigger: trigger}
+}
+
+func TestTriggersWatchUpdate(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		server    = &serverTriggers{Server: Server{Name: "prod"}}
+		refreshed = map[string]bool{"prod": true}
+		watch     = &triggersWatch{}
+	)
+
+	watch.update([]triggerRow{
+		newWatchRow(server, "1", "10", "1", "4"),
+		newWatchRow(server, "2", "20", "1", "2"),
+		newWatchRow(server, "3", "30", "0", "5"),
+	}, refreshed, time.Now())
+
+	test.Empty(watch.fresh, "the first refresh has no fresh problems")
+	test.Empty(watch.resolved)
+	test.Equal("DISASTER: 0  HIGH: 1  AVG: 0  WARN: 1  INFO: 0", watch.summary())
+
+	watch.update([]triggerRow{
+		newWatchRow(server, "1", "10", "1", "4"),
+		newWatchRow(server, "2", "20", "0", "2"),
+		newWatchRow(server, "3", "31", "1", "5"),
+	}, refreshed, time.Now())
+
+	test.Equal(map[string]bool{"prod/3/31": true}, watch.fresh)
+	if test.Len(watch.resolved, 1) {
+		test.Equal("2", watch.resolved[0].trigger.ID)
+	}
+
+	test.Equal("DISASTER: 1  HIGH: 1  AVG: 0  WARN: 0  INFO: 0", watch.summary())
+
+	var buffer bytes.Buffer
+	watch.draw(&buffer, time.Second*30, ExtendedOutputNone, false)
+
+	lines := strings.Split(buffer.String(), "\n")
+	test.Contains(lines[0], "Every 30s")
+	test.True(strings.HasPrefix(lines[4], watchHighlight+"31"), lines[4])
+	test.Contains(buffer.String(), "Resolved since the previous refresh:\n20")
+
+	watch.update(watch.rows, refreshed, time.Now())
+
+	test.Empty(watch.fresh)
+	test.Empty(watch.resolved)
+}
+
+func TestTriggersWatchUpdate_FailedServer(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		prod  = &serverTriggers{Server: Server{Name: "prod"}}
+		lab   = &serverTriggers{Server: Server{Name: "lab"}}
+		watch = &triggersWatch{}
+	)
+
+	watch.update([]triggerRow{
+		newWatchRow(prod, "1", "10", "1", "4"),
+		newWatchRow(lab, "1", "10", "1", "4"),
+	}, map[string]bool{"prod": true, "lab": true}, time.Now())
+
+	// lab can't be reached, its problems aren't resolved
+	watch.update([]triggerRow{
+		newWatchRow(prod, "1", "10", "1", "4"),
+	}, map[string]bool{"prod": true}, time.Now())
+
+	test.Empty(watch.fresh)
+	test.Empty(watch.resolved)
+	test.Len(watch.problems, 2)
+	test.Equal("DISASTER: 0  HIGH: 2  AVG: 0  WARN: 0  INFO: 0", watch.summary())
+
+	// lab is back without its problem
+	watch.update([]triggerRow{
+		newWatchRow(prod, "1", "10", "1", "4"),
+	}, map[string]bool{"prod": true, "lab": true}, time.Now())
+
+	if test.Len(watch.resolved, 1) {
+		test.Equal("lab", watch.resolved[0].Name)
+	}
+
+	test.Len(watch.problems, 1)
+}
+
+func TestTriggersWatchReport(t *testing.T) {
+	test := assert.New(t)
+
+	watch := &triggersWatch{}
+	watch.report(":: %s, context %s is skipped", "can't obtain zabbix triggers", "lab")
+	watch.report(":: Hooks failed: %s", "timeout")
+
+	var buffer bytes.Buffer
+	watch.draw(&buffer, time.Second*30, ExtendedOutputNone, true)
+
+	test.Contains(
+		buffer.String(),
+		"\n:: can't obtain zabbix triggers, context lab is skipped\n"+
+			":: Hooks failed: timeout\n",
+	)
+}
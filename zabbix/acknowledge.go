@@ -0,0 +1,33 @@
+package zabbix
+
+// event.acknowledge action bits, as of v4.0
+const (
+	acknowledgeActionClose         = 1
+	acknowledgeActionAcknowledge   = 2
+	acknowledgeActionMessage       = 4
+	acknowledgeActionSeverity      = 8
+	acknowledgeActionUnacknowledge = 16
+	acknowledgeActionSuppress      = 32
+	acknowledgeActionUnsuppress    = 64
+)
+
+// AcknowledgeOptions describe how Acknowledge updates events, every set field
+// adds the corresponding action.
+type AcknowledgeOptions struct {
+	Acknowledge   bool
+	Unacknowledge bool
+
+	// Message is added to events when it's not empty.
+	Message string
+
+	// Close closes problems, the trigger must allow manual close.
+	Close bool
+
+	// Severity changes severity of events when it's not nil.
+	Severity *Severity
+
+	// Suppress suppresses events until SuppressUntil (unix time), zero
+	// means indefinitely. Zabbix 6.4+ is required.
+	Suppress      bool
+	SuppressUntil int64
+}
@@ -0,0 +1,105 @@
+package zabbix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcknowledgeParams(t *testing.T) {
+	test := assert.New(t)
+
+	high := SeverityHigh
+
+	testcases := []struct {
+		version string
+		options AcknowledgeOptions
+		params  Params
+		err     string
+	}{
+		{
+			version: "3.0.0",
+			options: AcknowledgeOptions{Acknowledge: true},
+			params:  Params{"eventids": []string{"1"}, "message": "ack"},
+		},
+		{
+			version: "3.0.0",
+			options: AcknowledgeOptions{Acknowledge: true, Close: true},
+			err:     "4.0+ is required",
+		},
+		{
+			version: "6.0.0",
+			options: AcknowledgeOptions{Acknowledge: true},
+			params:  Params{"eventids": []string{"1"}, "action": 2},
+		},
+		{
+			version: "6.0.0",
+			options: AcknowledgeOptions{
+				Acknowledge: true,
+				Message:     "on it",
+				Close:       true,
+				Severity:    &high,
+			},
+			params: Params{
+				"eventids": []string{"1"},
+				"action":   15,
+				"message":  "on it",
+				"severity": 4,
+			},
+		},
+		{
+			version: "4.0.0",
+			options: AcknowledgeOptions{Unacknowledge: true},
+			err:     "5.0+ is required",
+		},
+		{
+			version: "5.0.0",
+			options: AcknowledgeOptions{Unacknowledge: true},
+			params:  Params{"eventids": []string{"1"}, "action": 16},
+		},
+		{
+			version: "6.0.0",
+			options: AcknowledgeOptions{Suppress: true},
+			err:     "6.4+ is required",
+		},
+		{
+			version: "6.4.0",
+			options: AcknowledgeOptions{
+				Acknowledge:   true,
+				Suppress:      true,
+				SuppressUntil: 1700000000,
+			},
+			params: Params{
+				"eventids":       []string{"1"},
+				"action":         34,
+				"suppress_until": int64(1700000000),
+			},
+		},
+		{
+			version: "6.4.0",
+			options: AcknowledgeOptions{},
+			err:     "no action",
+		},
+		{
+			version: "6.4.0",
+			options: AcknowledgeOptions{Acknowledge: true, Unacknowledge: true},
+			err:     "at once",
+		},
+	}
+
+	for _, testcase := range testcases {
+		zabbix := &Zabbix{apiVersion: testcase.version}
+
+		params, err := zabbix.acknowledgeParams([]string{"1"}, testcase.options)
+		if testcase.err != "" {
+			if test.Error(err, testcase.version) {
+				test.Contains(err.Error(), testcase.err)
+			}
+
+			continue
+		}
+
+		test.NoError(err, testcase.version)
+		test.Equal(testcase.params, params, testcase.version)
+	}
+}
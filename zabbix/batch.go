@@ -0,0 +1,145 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/reconquest/karma-go"
+)
+
+// batchCall is a single method call of the JSON-RPC batch request.
+type batchCall struct {
+	method   string
+	params   interface{}
+	response Response
+}
+
+// batch performs authorized calls in a single JSON-RPC batch request, every
+// call gets its own response. If the server rejects the session the user is
+// authorized again and the whole batch is retried once.
+func (zabbix *Zabbix) batch(ctx context.Context, calls []batchCall) error {
+	session := zabbix.getSession()
+
+	err := zabbix.requestBatch(ctx, calls)
+	if err == nil || zabbix.token || zabbix.username == "" {
+		return err
+	}
+
+	expired := false
+	for _, call := range calls {
+		if call.response.SessionExpired() {
+			expired = true
+			break
+		}
+	}
+
+	if !expired {
+		return err
+	}
+
+	zabbix.debugf("* session was rejected by the server, re-authorizing")
+
+	err = zabbix.relogin(ctx, session)
+	if err != nil {
+		return karma.Format(
+			err,
+			"can't re-authorize user '%s' on server %s",
+			zabbix.username, zabbix.basicURL,
+		)
+	}
+
+	// responses still hold errors of the first attempt
+	for _, call := range calls {
+		reflect.ValueOf(call.response).Elem().Set(
+			reflect.Zero(reflect.TypeOf(call.response).Elem()),
+		)
+	}
+
+	return zabbix.requestBatch(ctx, calls)
+}
+
+func (zabbix *Zabbix) requestBatch(ctx context.Context, calls []batchCall) error {
+	useBearerToken, err := zabbix.useBearerToken(withAuthFlag)
+	if err != nil {
+		return err
+	}
+
+	var (
+		requests = make([]Request, len(calls))
+		indexes  = map[int64]int{}
+	)
+
+	for index, call := range calls {
+		requests[index] = zabbix.newRequest(
+			call.method, call.params, withAuthFlag, useBearerToken,
+		)
+
+		indexes[requests[index].ID] = index
+	}
+
+	body, err := zabbix.post(ctx, requests, useBearerToken)
+	if err != nil {
+		return err
+	}
+
+	var results []json.RawMessage
+	err = json.Unmarshal(body, &results)
+	if err != nil {
+		// the whole batch can be rejected with a single error
+		var response ResponseRaw
+		if json.Unmarshal(body, &response) == nil && response.Error() != nil {
+			return karma.Format(
+				response.Error(),
+				"zabbix returned error while working with batch request",
+			)
+		}
+
+		return karma.Format(err, "can't decode batch response")
+	}
+
+	// responses are matched by identifiers, the order isn't guaranteed
+	for _, result := range results {
+		var header struct {
+			ID int64 `json:"id"`
+		}
+
+		err = json.Unmarshal(result, &header)
+		if err != nil {
+			return karma.Format(err, "can't decode batch response")
+		}
+
+		index, ok := indexes[header.ID]
+		if !ok {
+			return fmt.Errorf("unexpected id %d in batch response", header.ID)
+		}
+
+		delete(indexes, header.ID)
+
+		err = decodeResponse(result, calls[index].response)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(indexes) > 0 {
+		return fmt.Errorf(
+			"batch response misses %d of %d responses",
+			len(indexes), len(calls),
+		)
+	}
+
+	for _, call := range calls {
+		err = call.response.Error()
+		if err != nil {
+			return karma.Format(
+				err,
+				"zabbix returned error while working with api method %s",
+				call.method,
+			)
+		}
+	}
+
+	return nil
+}
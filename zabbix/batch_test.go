@@ -0,0 +1,156 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newHistoryTestServer returns a server which answers history.get batches in
+// the reversed order, the value of the item is its id, items with odd ids have
+// no history. Sizes of received batches are collected in batches.
+func newHistoryTestServer(
+	mutex *sync.Mutex,
+	batches *[]int,
+	reject *bool,
+) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var requests []Request
+			err := json.NewDecoder(r.Body).Decode(&requests)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			mutex.Lock()
+			*batches = append(*batches, len(requests))
+			rejected := *reject
+			mutex.Unlock()
+
+			responses := []interface{}{}
+			for index := len(requests) - 1; index >= 0; index-- {
+				request := requests[index]
+
+				if rejected {
+					responses = append(responses, map[string]interface{}{
+						"jsonrpc": "2.0",
+						"error": map[string]interface{}{
+							"code":    -32602,
+							"message": "Invalid params.",
+							"data":    "Session terminated, re-login, please.",
+						},
+						"id": request.ID,
+					})
+
+					continue
+				}
+
+				params := request.Params.(map[string]interface{})
+				id := params["itemids"].(string)
+
+				number, _ := strconv.Atoi(id)
+
+				result := []interface{}{}
+				if number%2 == 0 {
+					result = append(result, map[string]interface{}{
+						"itemid": id,
+						"value":  id,
+						"clock":  params["history"],
+					})
+				}
+
+				responses = append(responses, map[string]interface{}{
+					"jsonrpc": "2.0",
+					"result":  result,
+					"id":      request.ID,
+				})
+			}
+
+			json.NewEncoder(w).Encode(responses)
+		},
+	))
+}
+
+func TestGetLastHistory(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		mutex   sync.Mutex
+		batches []int
+		reject  bool
+	)
+
+	testserver := newHistoryTestServer(&mutex, &batches, &reject)
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "session"
+
+	items := []Item{}
+	for id := 1; id <= HistoryBatchSize*2+10; id++ {
+		items = append(items, Item{
+			ID:        strconv.Itoa(id),
+			ValueType: strconv.Itoa(id % 5),
+		})
+	}
+
+	history, err := zabbix.GetLastHistory(context.Background(), items)
+	test.NoError(err)
+	test.Len(history, len(items)/2)
+
+	for _, item := range items {
+		value, ok := history[item.ID]
+
+		number, _ := strconv.Atoi(item.ID)
+		if number%2 != 0 {
+			test.False(ok, "item %s has no history", item.ID)
+			continue
+		}
+
+		if test.True(ok, "item %s has history", item.ID) {
+			test.Equal(item.ID, value.ItemID)
+			test.Equal(item.ID, value.String())
+			test.Equal(fmt.Sprint(item.ValueType), value.Clock)
+		}
+	}
+
+	test.ElementsMatch([]int{HistoryBatchSize, HistoryBatchSize, 10}, batches)
+}
+
+func TestGetLastHistoryError(t *testing.T) {
+	test := assert.New(t)
+
+	var (
+		mutex   sync.Mutex
+		batches []int
+		reject  = true
+	)
+
+	testserver := newHistoryTestServer(&mutex, &batches, &reject)
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "session"
+
+	// without credentials the rejected session can't be renewed
+	_, err := zabbix.GetLastHistory(
+		context.Background(),
+		[]Item{{ID: "1", ValueType: "0"}, {ID: "2", ValueType: "3"}},
+	)
+	test.Error(err)
+	test.Contains(err.Error(), "Session terminated")
+}
@@ -0,0 +1,80 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	methodNotFound = `
+{
+    "jsonrpc": "2.0",
+    "error": {
+        "code": -32601,
+        "message": "Method not found.",
+        "data": "Incorrect API \"foo\"."
+    },
+    "id": 1
+}`
+)
+
+func TestCall(t *testing.T) {
+	test := assert.New(t)
+
+	var requests []Request
+
+	testserver := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var request Request
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			requests = append(requests, request)
+
+			switch request.Method {
+			case "apiinfo.version":
+				fmt.Fprint(w, `{"jsonrpc": "2.0", "result": "6.0.0", "id": 1}`)
+			case "host.get":
+				fmt.Fprint(w, hostsGet)
+			default:
+				fmt.Fprint(w, methodNotFound)
+			}
+		},
+	))
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "session"
+
+	result, err := zabbix.Call(
+		context.Background(), "host.get", json.RawMessage(`{"output": ["host"]}`),
+	)
+	test.NoError(err)
+
+	var hosts []Host
+	test.NoError(json.Unmarshal(result, &hosts))
+	test.Len(hosts, 2)
+	test.Equal("session", requests[0].Auth)
+
+	result, err = zabbix.Call(context.Background(), "apiinfo.version", Params{})
+	test.NoError(err)
+	test.JSONEq(`"6.0.0"`, string(result))
+	test.Empty(requests[1].Auth)
+
+	_, err = zabbix.Call(context.Background(), "foo.get", Params{})
+	test.IsType(&APIError{}, err)
+	test.Equal(-32601, err.(*APIError).Code)
+	test.Equal("Method not found.", err.(*APIError).Message)
+}
@@ -0,0 +1,169 @@
+package zabbix
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EventValueOK      = "0"
+	EventValueProblem = "1"
+)
+
+// Event is a PROBLEM or OK event of a trigger.
+type Event struct {
+	ID           string        `json:"eventid"`
+	ObjectID     string        `json:"objectid"`
+	Clock        string        `json:"clock"`
+	Value        string        `json:"value"`
+	Name         string        `json:"name"`
+	Priority     string        `json:"severity"`
+	Acknowledged string        `json:"acknowledged"`
+	REventID     string        `json:"r_eventid"`
+	Acknowledges []Acknowledge `json:"acknowledges"`
+	Alerts       []Alert       `json:"alerts"`
+	Hosts        []struct {
+		Hostid string `json:"hostid"`
+		Name   string `json:"name"`
+	} `json:"hosts"`
+	RelatedObject struct {
+		ID          string `json:"triggerid"`
+		Description string `json:"description"`
+		Priority    string `json:"priority"`
+	} `json:"relatedObject"`
+
+	// RClock is the time of the OK event which resolved the problem, it's
+	// filled by GetEvents.
+	RClock string `json:"r_clock,omitempty"`
+}
+
+// Alert is a message sent by an action for the event.
+type Alert struct {
+	ID          string `json:"alertid"`
+	Clock       string `json:"clock"`
+	SendTo      string `json:"sendto"`
+	Subject     string `json:"subject"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	MediaTypeID string `json:"mediatypeid"`
+}
+
+func (event *Event) String() string {
+	return event.ID + " " + event.GetHostName() + " " + event.Name
+}
+
+func (event *Event) GetHostName() string {
+	if len(event.Hosts) > 0 {
+		return event.Hosts[0].Name
+	}
+	return "<missing>"
+}
+
+func (event *Event) Problem() bool {
+	return event.Value == EventValueProblem
+}
+
+// Severity returns the severity of the problem event, OK events have the
+// severity of their trigger.
+func (event *Event) Severity() Severity {
+	priority := event.Priority
+	if !event.Problem() && event.RelatedObject.Priority != "" {
+		priority = event.RelatedObject.Priority
+	}
+
+	value, _ := strconv.Atoi(priority)
+	return Severity(value)
+}
+
+func (event *Event) DateTime() string {
+	return unixTime(event.Clock).Format(TimeFormat)
+}
+
+func (event *Event) StatusProblem() string {
+	if event.Problem() {
+		return "PROBLEM"
+	}
+
+	return "OK"
+}
+
+func (event *Event) StatusAcknowledge() string {
+	if !event.Problem() {
+		return ""
+	}
+
+	if event.Acknowledged == "1" {
+		return "ACK"
+	}
+
+	return "NACK"
+}
+
+// Resolved reports whether the problem event has been resolved.
+func (event *Event) Resolved() bool {
+	return event.REventID != "" && event.REventID != "0"
+}
+
+// Duration returns how long the problem lasts or lasted if it's resolved,
+// it's empty for OK events.
+func (event *Event) Duration() string {
+	if !event.Problem() {
+		return ""
+	}
+
+	till := time.Now()
+	if event.Resolved() && event.RClock != "" {
+		till = unixTime(event.RClock)
+	}
+
+	return FormatDuration(till.Sub(unixTime(event.Clock)))
+}
+
+func (alert *Alert) DateTime() string {
+	return unixTime(alert.Clock).Format(TimeFormat)
+}
+
+func (alert *Alert) StatusString() string {
+	switch alert.Status {
+	case "0":
+		return "not sent"
+	case "1":
+		return "sent"
+	case "2":
+		return "failed"
+	case "3":
+		return "new"
+	default:
+		return "unknown"
+	}
+}
+
+// Actions returns names of actions of the update.
+func (acknowledge *Acknowledge) Actions() string {
+	var (
+		action, _ = strconv.Atoi(acknowledge.Action)
+		actions   = []string{}
+	)
+
+	names := []struct {
+		flag int
+		name string
+	}{
+		{acknowledgeActionClose, "closed"},
+		{acknowledgeActionAcknowledge, "acknowledged"},
+		{acknowledgeActionMessage, "commented"},
+		{acknowledgeActionSeverity, "changed severity"},
+		{acknowledgeActionUnacknowledge, "unacknowledged"},
+		{acknowledgeActionSuppress, "suppressed"},
+		{acknowledgeActionUnsuppress, "unsuppressed"},
+	}
+
+	for _, name := range names {
+		if action&name.flag != 0 {
+			actions = append(actions, name.name)
+		}
+	}
+
+	return strings.Join(actions, ", ")
+}
@@ -0,0 +1,118 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	eventsGet = `
+{
+    "jsonrpc": "2.0",
+    "result": [
+        {
+            "eventid": "12",
+            "objectid": "100",
+            "clock": "1700000000",
+            "value": "1",
+            "name": "High CPU",
+            "severity": "4",
+            "acknowledged": "1",
+            "r_eventid": "13",
+            "acknowledges": [
+                {"clock": "1700000100", "message": "looking", "username": "admin", "action": "6"}
+            ],
+            "alerts": [
+                {"alertid": "1", "clock": "1700000010", "sendto": "ops@example.com", "subject": "Problem", "status": "1"},
+                {"alertid": "2", "clock": "1700000010", "sendto": "+100", "subject": "Problem", "status": "2", "error": "timeout"}
+            ],
+            "hosts": [{"hostid": "1", "name": "db1"}],
+            "relatedObject": {"triggerid": "100", "description": "High CPU", "priority": "4"}
+        },
+        {
+            "eventid": "14",
+            "objectid": "100",
+            "clock": "1700007200",
+            "value": "0",
+            "name": "High CPU",
+            "severity": "0",
+            "acknowledged": "0",
+            "r_eventid": "0",
+            "acknowledges": [],
+            "alerts": [],
+            "hosts": [{"hostid": "1", "name": "db1"}],
+            "relatedObject": {"triggerid": "100", "description": "High CPU", "priority": "4"}
+        }
+    ],
+    "id": 1
+}`
+
+	eventsRecoveriesGet = `
+{
+    "jsonrpc": "2.0",
+    "result": [
+        {"eventid": "13", "clock": "1700003700"}
+    ],
+    "id": 2
+}`
+)
+
+func TestGetEvents(t *testing.T) {
+	test := assert.New(t)
+
+	requests := []Request{}
+
+	testserver := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var request Request
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			requests = append(requests, request)
+
+			switch {
+			case request.Method != "event.get":
+				fmt.Fprint(w, methodNotFound)
+			case len(requests) == 1:
+				fmt.Fprint(w, eventsGet)
+			default:
+				fmt.Fprint(w, eventsRecoveriesGet)
+			}
+		},
+	))
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+
+	events, err := zabbix.GetEvents(context.Background(), Params{
+		"objectids": "100",
+	})
+	test.NoError(err)
+	test.Len(events, 2)
+	test.Len(requests, 2)
+
+	test.Equal("PROBLEM", events[0].StatusProblem())
+	test.Equal("ACK", events[0].StatusAcknowledge())
+	test.Equal("db1", events[0].GetHostName())
+	test.Equal("1700003700", events[0].RClock)
+	test.Equal("1h 1m 40s", events[0].Duration())
+	test.Equal("acknowledged, commented", events[0].Acknowledges[0].Actions())
+	test.Equal("sent", events[0].Alerts[0].StatusString())
+	test.Equal("failed", events[0].Alerts[1].StatusString())
+
+	test.Equal("OK", events[1].StatusProblem())
+	test.Equal("", events[1].Duration())
+	test.Equal(SeverityHigh, events[1].Severity())
+}
@@ -0,0 +1,22 @@
+package zabbix
+
+import "strings"
+
+type Function struct {
+	ID        string `json:"functionid"`
+	ItemID    string `json:"itemid"`
+	TriggerID string `json:"triggerid"`
+	Name      string `json:"function"`
+	Parameter string `json:"parameter"`
+}
+
+// Call returns the function with its parameters, the item reference '$' used
+// by Zabbix 5.4+ is omitted, e.g. avg(5m).
+func (function *Function) Call() string {
+	parameter := function.Parameter
+	if parameter == "$" || strings.HasPrefix(parameter, "$,") {
+		parameter = strings.TrimPrefix(strings.TrimPrefix(parameter, "$"), ",")
+	}
+
+	return function.Name + "(" + parameter + ")"
+}
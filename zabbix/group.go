@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 type Group struct {
 	ID   string `json:"groupid"`
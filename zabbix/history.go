@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 import (
 	"fmt"
@@ -12,11 +12,10 @@ type History struct {
 	Clock  string      `json:"clock"`
 }
 
-// go vet complains: struct field ItemID repeats json tag "itemid" also at item.go:20
-// https://github.com/golang/go/issues/40102
+// ItemHistory pairs an item with its last history value.
 type ItemHistory struct {
-	Item
-	History
+	Item    Item    `json:"item"`
+	History History `json:"history"`
 }
 
 func (history *History) String() string {
@@ -24,10 +23,7 @@ func (history *History) String() string {
 }
 
 func (history *History) date() time.Time {
-	date, err := strconv.ParseInt(history.Clock, 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	date, _ := strconv.ParseInt(history.Clock, 10, 64)
 	return time.Unix(date, 0)
 }
 
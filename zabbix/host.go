@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 type Host struct {
 	ID   string `json:"hostid"`
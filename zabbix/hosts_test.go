@@ -1,6 +1,7 @@
-package main
+package zabbix
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -128,7 +129,7 @@ func TestHostsGet(t *testing.T) {
 	zabbix.apiURL = testserver.URL
 	zabbix.apiVersion = "5.0.0"
 
-	hosts, err := zabbix.GetHosts(Params{})
+	hosts, err := zabbix.GetHosts(context.Background(), Params{})
 
 	test.NoError(err)
 	test.Len(hosts, 2)
@@ -156,7 +157,7 @@ func TestHostsRemove(t *testing.T) {
 
 	var hostids Hosts
 	payload := []string{"13", "32"}
-	hostids, err := zabbix.RemoveHosts(payload)
+	hostids, err := zabbix.RemoveHosts(context.Background(), payload)
 
 	test.NoError(err)
 	test.Len(hostids.ID, 2)
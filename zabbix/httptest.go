@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 import (
 	"fmt"
@@ -35,10 +35,7 @@ func (check *HTTPTest) DateTime() string {
 }
 
 func (check *HTTPTest) date() time.Time {
-	date, err := strconv.ParseInt(check.NextCheck, 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	date, _ := strconv.ParseInt(check.NextCheck, 10, 64)
 	return time.Unix(date, 0)
 }
 
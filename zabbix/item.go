@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 import (
 	"fmt"
@@ -28,30 +28,35 @@ type Item struct {
 }
 
 func (item *Item) DateTime() string {
-	if item.getLastClock() == "0" {
+	clock, err := item.getLastClock()
+	if err != nil || clock == "0" {
 		return "-"
 	}
 
 	return item.date().Format(TimeFormat)
 }
 
-func (item *Item) getLastClock() string {
+func (item *Item) getLastClock() (string, error) {
 	switch typed := item.LastClock.(type) {
 	case string:
-		return typed
+		return typed, nil
 	case float64:
-		return fmt.Sprint(int64(typed))
+		return fmt.Sprint(int64(typed)), nil
+	case nil:
+		// lastclock isn't in the requested output
+		return "0", nil
 	default:
-		fatalf("unexpected type '%v' for lastclock in item ID %s (%s)\nplease file a bug report.", typed, item.ID, item.Name)
-		return "error"
+		return "", fmt.Errorf(
+			"unexpected type '%T' for lastclock in item ID %s (%s), "+
+				"please file a bug report",
+			typed, item.ID, item.Name,
+		)
 	}
 }
 
 func (item *Item) date() time.Time {
-	date, err := strconv.ParseInt(item.getLastClock(), 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	clock, _ := item.getLastClock()
+	date, _ := strconv.ParseInt(clock, 10, 64)
 	return time.Unix(date, 0)
 }
 
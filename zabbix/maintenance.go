@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 import (
 	"strconv"
@@ -48,9 +48,6 @@ func (maintenance *Maintenance) GetStatus() string {
 }
 
 func (maintenance *Maintenance) GetDateTime(unixtime string) string {
-	date, err := strconv.ParseInt(unixtime, 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	date, _ := strconv.ParseInt(unixtime, 10, 64)
 	return time.Unix(date, 0).Format("2006-01-02 15:04:05")
 }
@@ -1,6 +1,7 @@
-package main
+package zabbix
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -91,7 +92,7 @@ func TestMaintenanceGet(t *testing.T) {
 	zabbix.apiURL = testserver.URL
 	zabbix.apiVersion = "5.0.0"
 
-	maintenances, err := zabbix.GetMaintenances(Params{
+	maintenances, err := zabbix.GetMaintenances(context.Background(), Params{
 		"search": Params{
 			"name": "Sunday maintenance",
 		},
@@ -123,7 +124,7 @@ func TestMaintenanceRemove(t *testing.T) {
 	payload := []string{"3", "1"}
 
 	var maintenances Maintenances
-	maintenances, err := zabbix.RemoveMaintenance(payload)
+	maintenances, err := zabbix.RemoveMaintenance(context.Background(), payload)
 
 	test.NoError(err)
 	test.Len(maintenances.ID, 2)
@@ -167,7 +168,7 @@ func TestMaintenanceCreate(t *testing.T) {
 	}
 
 	var maintenances Maintenances
-	maintenances, err := zabbix.RemoveMaintenance(params)
+	maintenances, err := zabbix.RemoveMaintenance(context.Background(), params)
 
 	test.NoError(err)
 	test.Len(maintenances.ID, 1)
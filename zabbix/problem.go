@@ -0,0 +1,131 @@
+package zabbix
+
+import (
+	"strconv"
+	"time"
+)
+
+// Problem is a problem event of a trigger, the trigger can have several
+// problems at once when it generates multiple problem events.
+type Problem struct {
+	EventID      string        `json:"eventid"`
+	ObjectID     string        `json:"objectid"`
+	Clock        string        `json:"clock"`
+	Name         string        `json:"name"`
+	Priority     string        `json:"severity"`
+	Acknowledged string        `json:"acknowledged"`
+	Suppressed   string        `json:"suppressed"`
+	REventID     string        `json:"r_eventid"`
+	RClock       string        `json:"r_clock"`
+	Acknowledges []Acknowledge `json:"acknowledges"`
+	Tags         []Tag         `json:"tags"`
+
+	// Hosts are filled from the trigger of the problem.
+	Hosts []struct {
+		Hostid string `json:"hostid"`
+		Name   string `json:"name"`
+	} `json:"hosts"`
+}
+
+// Acknowledge is an update of the problem made by the user.
+type Acknowledge struct {
+	ID      string `json:"acknowledgeid"`
+	UserID  string `json:"userid"`
+	Clock   string `json:"clock"`
+	Message string `json:"message"`
+	Action  string `json:"action"`
+
+	// Username is returned as of v5.4, Alias before.
+	Username string `json:"username,omitempty"`
+	Alias    string `json:"alias,omitempty"`
+}
+
+func (problem *Problem) String() string {
+	return problem.EventID + " " + problem.GetHostName() + " " + problem.Name
+}
+
+func (problem *Problem) GetHostName() string {
+	if len(problem.Hosts) > 0 {
+		return problem.Hosts[0].Name
+	}
+	return "<missing>"
+}
+
+func (problem *Problem) Severity() Severity {
+	value, _ := strconv.Atoi(problem.Priority)
+	return Severity(value)
+}
+
+func (problem *Problem) DateTime() string {
+	return unixTime(problem.Clock).Format(TimeFormat)
+}
+
+// Resolved reports whether the problem has been resolved, which happens for
+// recently resolved problems only.
+func (problem *Problem) Resolved() bool {
+	return problem.REventID != "" && problem.REventID != "0"
+}
+
+func (problem *Problem) StatusProblem() string {
+	if problem.Resolved() {
+		return "RESOLVED"
+	}
+
+	return "PROBLEM"
+}
+
+func (problem *Problem) StatusAcknowledge() string {
+	if problem.Acknowledged == "1" {
+		return "ACK"
+	}
+
+	return "NACK"
+}
+
+// Duration returns how long the problem lasts or lasted if it's resolved.
+func (problem *Problem) Duration() string {
+	till := time.Now()
+	if problem.Resolved() {
+		till = unixTime(problem.RClock)
+	}
+
+	return FormatDuration(till.Sub(unixTime(problem.Clock)))
+}
+
+// LastAcknowledge returns the latest update of the problem which has
+// a message, nil if there is none.
+func (problem *Problem) LastAcknowledge() *Acknowledge {
+	var last *Acknowledge
+	for index, acknowledge := range problem.Acknowledges {
+		if acknowledge.Message == "" {
+			continue
+		}
+
+		if last == nil || acknowledge.Clock > last.Clock {
+			last = &problem.Acknowledges[index]
+		}
+	}
+
+	return last
+}
+
+func (problem *Problem) TagsString() string {
+	return tagsString(problem.Tags)
+}
+
+func (acknowledge *Acknowledge) User() string {
+	if acknowledge.Username != "" {
+		return acknowledge.Username
+	}
+
+	return acknowledge.Alias
+}
+
+func (acknowledge *Acknowledge) DateTime() string {
+	return unixTime(acknowledge.Clock).Format(TimeFormat)
+}
+
+func unixTime(value string) time.Time {
+	date, _ := strconv.ParseInt(value, 10, 64)
+	return time.Unix(date, 0)
+}
@@ -0,0 +1,126 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	problemsGet = `
+{
+    "jsonrpc": "2.0",
+    "result": [
+        {
+            "eventid": "12",
+            "objectid": "100",
+            "clock": "1700000000",
+            "name": "High CPU",
+            "severity": "4",
+            "acknowledged": "1",
+            "r_eventid": "13",
+            "r_clock": "1700003700",
+            "acknowledges": [
+                {"acknowledgeid": "1", "userid": "1", "clock": "1700000100", "message": "looking", "action": "6"},
+                {"acknowledgeid": "2", "userid": "1", "clock": "1700000200", "message": "", "action": "2"},
+                {"acknowledgeid": "3", "userid": "2", "clock": "1700000150", "message": "fixed", "action": "4"}
+            ],
+            "tags": [{"tag": "service", "value": "db"}, {"tag": "prod", "value": ""}]
+        },
+        {
+            "eventid": "11",
+            "objectid": "101",
+            "clock": "1700000000",
+            "name": "Disk full",
+            "severity": "2",
+            "acknowledged": "0",
+            "r_eventid": "0",
+            "acknowledges": [],
+            "tags": []
+        }
+    ],
+    "id": 1
+}`
+
+	problemUsersGet = `
+{
+    "jsonrpc": "2.0",
+    "result": [
+        {"userid": "1", "username": "Admin"},
+        {"userid": "2", "username": "guest"}
+    ],
+    "id": 3
+}`
+
+	problemTriggersGet = `
+{
+    "jsonrpc": "2.0",
+    "result": {
+        "100": {"triggerid": "100", "hosts": [{"hostid": "1", "name": "db1"}]},
+        "101": {"triggerid": "101", "hosts": [{"hostid": "2", "name": "web1"}]}
+    },
+    "id": 2
+}`
+)
+
+func TestGetProblems(t *testing.T) {
+	test := assert.New(t)
+
+	testserver := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var request Request
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			switch request.Method {
+			case "problem.get":
+				fmt.Fprint(w, problemsGet)
+			case "trigger.get":
+				fmt.Fprint(w, problemTriggersGet)
+			case "user.get":
+				fmt.Fprint(w, problemUsersGet)
+			default:
+				fmt.Fprint(w, methodNotFound)
+			}
+		},
+	))
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+
+	problems, err := zabbix.GetProblems(context.Background(), Params{})
+	test.NoError(err)
+	test.Len(problems, 2)
+
+	test.Equal("db1", problems[0].GetHostName())
+	test.Equal("web1", problems[1].GetHostName())
+
+	test.True(problems[0].Resolved())
+	test.Equal("RESOLVED", problems[0].StatusProblem())
+	test.Equal("1h 1m 40s", problems[0].Duration())
+	test.Equal("service:db prod", problems[0].TagsString())
+	test.Equal(SeverityHigh, problems[0].Severity())
+
+	last := problems[0].LastAcknowledge()
+	if test.NotNil(last) {
+		test.Equal("fixed", last.Message)
+		test.Equal("guest", last.User())
+	}
+
+	test.Equal("Admin", problems[0].Acknowledges[0].User())
+
+	test.False(problems[1].Resolved())
+	test.Equal("NACK", problems[1].StatusAcknowledge())
+	test.Nil(problems[1].LastAcknowledge())
+}
@@ -1,13 +1,29 @@
-package main
+package zabbix
 
-import "github.com/reconquest/karma-go"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/reconquest/karma-go"
+)
+
+// sessionErrors are the error details returned by the server when the given
+// session was terminated or has expired.
+var sessionErrors = []string{
+	"Session terminated",
+	"Not authorised",
+	"Not authorized",
+}
 
 type Response interface {
 	Error() error
+	SessionExpired() bool
 }
 
 type ResponseRaw struct {
 	Err struct {
+		Code    int    `json:"code"`
 		Data    string `json:"data"`
 		Message string `json:"message"`
 	} `json:"error"`
@@ -26,6 +42,39 @@ func (response *ResponseRaw) Error() error {
 	return nil
 }
 
+// SessionExpired reports whether the server rejected the request because the
+// session isn't valid anymore.
+func (response *ResponseRaw) SessionExpired() bool {
+	for _, reason := range sessionErrors {
+		if strings.Contains(response.Err.Data, reason) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// APIError is an error returned by the server for the requested method.
+type APIError struct {
+	Method  string
+	Code    int
+	Message string
+	Data    string
+}
+
+func (err *APIError) Error() string {
+	return fmt.Sprintf(
+		"zabbix returned error while working with api method %s: %s %s",
+		err.Method, err.Message, err.Data,
+	)
+}
+
+// ResponseJSON keeps the result of the method undecoded.
+type ResponseJSON struct {
+	ResponseRaw
+	Data json.RawMessage `json:"result"`
+}
+
 type ResponseLogin struct {
 	ResponseRaw
 	Token string `json:"result"`
@@ -78,6 +127,11 @@ type ResponseGroups struct {
 	Data []Group `json:"result"`
 }
 
+type ResponseTemplates struct {
+	ResponseRaw
+	Data []Template `json:"result"`
+}
+
 type ResponseUserGroup struct {
 	ResponseRaw
 	Data []UserGroup `json:"result"`
@@ -92,3 +146,18 @@ type ResponseHistory struct {
 	ResponseRaw
 	Data []History `json:"result"`
 }
+
+type ResponseProblems struct {
+	ResponseRaw
+	Data []Problem `json:"result"`
+}
+
+type ResponseSettings struct {
+	ResponseRaw
+	Data map[string]string `json:"result"`
+}
+
+type ResponseEvents struct {
+	ResponseRaw
+	Data []Event `json:"result"`
+}
@@ -0,0 +1,231 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sessionTerminated = `
+{
+    "jsonrpc": "2.0",
+    "error": {
+        "code": -32602,
+        "message": "Invalid params.",
+        "data": "Session terminated, re-login, please."
+    },
+    "id": 1
+}`
+
+	userLogin = `
+{
+    "jsonrpc": "2.0",
+    "result": "fresh",
+    "id": 1
+}`
+)
+
+// newSessionTestServer returns a server which accepts only the session
+// obtained through user.login, when reject is set it rejects every session.
+func newSessionTestServer(logins *int, reject bool) *httptest.Server {
+	var mutex sync.Mutex
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var request Request
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case request.Method == "user.login":
+				*logins++
+				fmt.Fprint(w, userLogin)
+			case request.Auth != "fresh" || reject:
+				fmt.Fprint(w, sessionTerminated)
+			default:
+				fmt.Fprint(w, hostsGet)
+			}
+		},
+	))
+}
+
+func TestSessionExpiredRelogin(t *testing.T) {
+	test := assert.New(t)
+
+	var logins int
+	testserver := newSessionTestServer(&logins, false)
+	defer testserver.Close()
+
+	sessionFile := filepath.Join(t.TempDir(), "session")
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "expired"
+	zabbix.username = "admin"
+	zabbix.password = "password"
+	zabbix.sessionFile = sessionFile
+
+	hosts, err := zabbix.GetHosts(context.Background(), Params{})
+
+	test.NoError(err)
+	test.Len(hosts, 2)
+	test.Equal("Zabbix server", hosts[0].Name)
+	test.Equal(1, logins)
+	test.Equal("fresh", zabbix.session)
+
+	session, err := os.ReadFile(sessionFile)
+	test.NoError(err)
+	test.Equal("fresh", string(session))
+}
+
+func TestSessionExpiredParallelRelogin(t *testing.T) {
+	test := assert.New(t)
+
+	var logins int
+	testserver := newSessionTestServer(&logins, false)
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "expired"
+	zabbix.username = "admin"
+	zabbix.password = "password"
+
+	var (
+		group sync.WaitGroup
+		errs  = make([]error, HistoryBatchConcurrency)
+	)
+
+	for index := range errs {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+
+			_, errs[index] = zabbix.GetHosts(context.Background(), Params{})
+		}(index)
+	}
+
+	group.Wait()
+
+	for _, err := range errs {
+		test.NoError(err)
+	}
+
+	test.Equal(1, logins)
+	test.Equal("fresh", zabbix.session)
+}
+
+func TestSessionExpiredRetriedOnce(t *testing.T) {
+	test := assert.New(t)
+
+	var logins int
+	testserver := newSessionTestServer(&logins, true)
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "expired"
+	zabbix.username = "admin"
+	zabbix.password = "password"
+
+	_, err := zabbix.GetHosts(context.Background(), Params{})
+
+	test.Error(err)
+	test.Equal(1, logins)
+}
+
+func TestSessionExpiredWithoutCredentials(t *testing.T) {
+	test := assert.New(t)
+
+	var logins int
+	testserver := newSessionTestServer(&logins, false)
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+	zabbix.session = "expired"
+
+	_, err := zabbix.GetHosts(context.Background(), Params{})
+
+	test.Error(err)
+	test.Equal(0, logins)
+}
+
+func TestTokenAuthorization(t *testing.T) {
+	test := assert.New(t)
+
+	for version, expected := range map[string]Request{
+		"6.0.0": {Auth: "token"},
+		"6.4.0": {},
+	} {
+		var (
+			logins  int
+			request Request
+			bearer  string
+		)
+
+		testserver := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&request)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				switch request.Method {
+				case "apiinfo.version":
+					fmt.Fprintf(w, `{"jsonrpc": "2.0", "result": %q, "id": 1}`, version)
+				case "user.login":
+					logins++
+					fmt.Fprint(w, userLogin)
+				default:
+					bearer = r.Header.Get("Authorization")
+					fmt.Fprint(w, hostsGet)
+				}
+			},
+		))
+
+		zabbix, err := NewZabbix(context.Background(), Options{
+			Address:     testserver.URL,
+			Token:       "token",
+			SessionFile: filepath.Join(t.TempDir(), "session"),
+		})
+		test.NoError(err)
+
+		_, err = zabbix.GetHosts(context.Background(), Params{})
+		test.NoError(err)
+
+		test.Equal(0, logins, version)
+		test.Equal(expected.Auth, request.Auth, version)
+		if expected.Auth == "" {
+			test.Equal("Bearer token", bearer, version)
+		} else {
+			test.Empty(bearer, version)
+		}
+
+		testserver.Close()
+	}
+}
@@ -0,0 +1,149 @@
+package zabbix
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Severity int
+
+var (
+	SeverityNotClassified Severity = 0
+	SeverityInformation   Severity = 1
+	SeverityWarning       Severity = 2
+	SeverityAverage       Severity = 3
+	SeverityHigh          Severity = 4
+	SeverityDisaster      Severity = 5
+)
+
+func (priority Severity) String() string {
+	switch priority {
+	case SeverityInformation:
+		return "INFO"
+	case SeverityWarning:
+		return "WARN"
+	case SeverityAverage:
+		return "AVG"
+	case SeverityHigh:
+		return "HIGH"
+	case SeverityDisaster:
+		return "DISASTER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseSeverity parses the severity given by its number, its short name as
+// returned by String or its full name, for example: 4, high, warn, warning.
+func ParseSeverity(value string) (Severity, error) {
+	number, err := strconv.Atoi(value)
+	if err == nil && number >= int(SeverityNotClassified) &&
+		number <= int(SeverityDisaster) {
+		return Severity(number), nil
+	}
+
+	switch strings.ToLower(value) {
+	case "not classified", "none":
+		return SeverityNotClassified, nil
+	case "info", "information":
+		return SeverityInformation, nil
+	case "warn", "warning":
+		return SeverityWarning, nil
+	case "avg", "average":
+		return SeverityAverage, nil
+	case "high":
+		return SeverityHigh, nil
+	case "disaster":
+		return SeverityDisaster, nil
+	}
+
+	return 0, fmt.Errorf(
+		"unexpected severity '%s', expected 0-5 or one of: "+
+			"none, info, warn, avg, high, disaster",
+		value,
+	)
+}
+
+// ParseSeverityRange parses severities given by the name or the number: the
+// severity and above, '=severity' for the severity only or 'from..till' for
+// the range, the omitted end of the range is the lowest or the highest
+// severity. Names customized on the server are accepted as well, they are
+// returned by GetSeverityNames.
+func ParseSeverityRange(value string, names map[Severity]string) ([]Severity, error) {
+	var (
+		from = SeverityNotClassified
+		till = SeverityDisaster
+		err  error
+	)
+
+	switch {
+	case strings.HasPrefix(value, "="):
+		from, err = parseSeverityName(value[1:], names)
+		till = from
+
+	case strings.Contains(value, ".."):
+		bounds := strings.SplitN(value, "..", 2)
+
+		if strings.TrimSpace(bounds[0]) != "" {
+			from, err = parseSeverityName(bounds[0], names)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if strings.TrimSpace(bounds[1]) != "" {
+			till, err = parseSeverityName(bounds[1], names)
+		}
+
+	default:
+		from, err = parseSeverityName(value, names)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if from > till {
+		return nil, fmt.Errorf(
+			"unexpected severity range '%s', %s is above %s",
+			value, from, till,
+		)
+	}
+
+	severities := []Severity{}
+	for severity := from; severity <= till; severity++ {
+		severities = append(severities, severity)
+	}
+
+	return severities, nil
+}
+
+func parseSeverityName(value string, names map[Severity]string) (Severity, error) {
+	value = strings.TrimSpace(value)
+
+	severity, err := ParseSeverity(value)
+	if err == nil {
+		return severity, nil
+	}
+
+	for severity, name := range names {
+		if strings.EqualFold(name, value) {
+			return severity, nil
+		}
+	}
+
+	if len(names) == 0 {
+		return 0, err
+	}
+
+	custom := []string{}
+	for severity := SeverityNotClassified; severity <= SeverityDisaster; severity++ {
+		custom = append(custom, names[severity])
+	}
+
+	return 0, fmt.Errorf(
+		"%s, or one of names configured on the server: %s",
+		err, strings.Join(custom, ", "),
+	)
+}
@@ -0,0 +1,103 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSeverityRange(t *testing.T) {
+	test := assert.New(t)
+
+	names := map[Severity]string{
+		SeverityWarning:  "Minor",
+		SeverityDisaster: "Critical",
+	}
+
+	testcases := []struct {
+		value      string
+		severities []Severity
+		err        bool
+	}{
+		{"high", []Severity{SeverityHigh, SeverityDisaster}, false},
+		{"=disaster", []Severity{SeverityDisaster}, false},
+		{"warn..high", []Severity{
+			SeverityWarning, SeverityAverage, SeverityHigh,
+		}, false},
+		{"..info", []Severity{SeverityNotClassified, SeverityInformation}, false},
+		{"avg..", []Severity{
+			SeverityAverage, SeverityHigh, SeverityDisaster,
+		}, false},
+		{"2..3", []Severity{SeverityWarning, SeverityAverage}, false},
+		{"minor..average", []Severity{SeverityWarning, SeverityAverage}, false},
+		{"=Critical", []Severity{SeverityDisaster}, false},
+		{"high..warn", nil, true},
+		{"major", nil, true},
+		{"=", nil, true},
+	}
+
+	for _, testcase := range testcases {
+		severities, err := ParseSeverityRange(testcase.value, names)
+		if testcase.err {
+			test.Error(err, testcase.value)
+			continue
+		}
+
+		test.NoError(err, testcase.value)
+		test.Equal(testcase.severities, severities, testcase.value)
+	}
+
+	_, err := ParseSeverityRange("minor", nil)
+	test.Error(err)
+}
+
+func TestGetSeverityNames(t *testing.T) {
+	test := assert.New(t)
+
+	testserver := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var request Request
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if request.Method != "settings.get" {
+				fmt.Fprint(w, methodNotFound)
+				return
+			}
+
+			fmt.Fprint(w, `{"jsonrpc": "2.0", "result": {
+				"severity_name_0": "Not classified",
+				"severity_name_1": "Information",
+				"severity_name_2": "Minor",
+				"severity_name_3": "Average",
+				"severity_name_4": "Major",
+				"severity_name_5": "Critical"
+			}, "id": 1}`)
+		},
+	))
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "6.0.0"
+
+	names, err := zabbix.GetSeverityNames(context.Background())
+	test.NoError(err)
+	test.Equal("Minor", names[SeverityWarning])
+	test.Equal("Critical", names[SeverityDisaster])
+
+	zabbix.apiVersion = "5.0.0"
+
+	names, err = zabbix.GetSeverityNames(context.Background())
+	test.NoError(err)
+	test.Nil(names)
+}
@@ -0,0 +1,54 @@
+package zabbix
+
+import "strings"
+
+// Tag is a tag of a trigger, a problem or an event.
+type Tag struct {
+	Tag   string `json:"tag"`
+	Value string `json:"value"`
+}
+
+// TagOperator is the operator of TagFilter.
+type TagOperator int
+
+const (
+	TagOperatorContains TagOperator = iota
+	TagOperatorEquals
+	TagOperatorNotContains
+	TagOperatorNotEquals
+	TagOperatorExists
+	TagOperatorNotExists
+)
+
+// TagFilter filters objects by tags, it's used in the tags parameter of
+// trigger.get and problem.get. Operators other than contains and equals
+// require Zabbix 5.4+.
+type TagFilter struct {
+	Tag      string      `json:"tag"`
+	Value    string      `json:"value"`
+	Operator TagOperator `json:"operator"`
+}
+
+// Evaluation types of tag filters, with TagEvalAndOr filters of the same
+// tag are combined by OR and filters of different tags by AND.
+const (
+	TagEvalAndOr = 0
+	TagEvalOr    = 2
+)
+
+func (tag *Tag) String() string {
+	if tag.Value == "" {
+		return tag.Tag
+	}
+
+	return tag.Tag + ":" + tag.Value
+}
+
+func tagsString(tags []Tag) string {
+	values := []string{}
+	for _, tag := range tags {
+		values = append(values, tag.String())
+	}
+
+	return strings.Join(values, " ")
+}
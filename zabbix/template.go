@@ -0,0 +1,7 @@
+package zabbix
+
+type Template struct {
+	ID   string `json:"templateid"`
+	Host string `json:"host"`
+	Name string `json:"name"`
+}
@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 import (
 	"strconv"
@@ -34,17 +34,11 @@ func (timeperiod *Timeperiod) GetType() string {
 }
 
 func (timeperiod *Timeperiod) GetStartDate() string {
-	date, err := strconv.ParseInt(timeperiod.StartDate, 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	date, _ := strconv.ParseInt(timeperiod.StartDate, 10, 64)
 	return time.Unix(date, 0).Format("2006-01-02 15:04:05")
 }
 
 func (timeperiod *Timeperiod) GetPeriodMinute() int64 {
-	period, err := strconv.ParseInt(timeperiod.Period, 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	period, _ := strconv.ParseInt(timeperiod.Period, 10, 64)
 	return (period / 60)
 }
@@ -1,4 +1,4 @@
-package main
+package zabbix
 
 import (
 	"strconv"
@@ -6,12 +6,20 @@ import (
 	"time"
 )
 
+// trigger.update statuses of triggers
+const (
+	TriggerStatusEnabled  = "0"
+	TriggerStatusDisabled = "1"
+)
+
 type Trigger struct {
 	ID          string     `json:"triggerid"`
 	Description string     `json:"description"`
 	Hostname    string     `json:"host"`
 	Value       string     `json:"value"`
+	Status      string     `json:"status"`
 	Comments    string     `json:"comments"`
+	Expression  string     `json:"expression"`
 	Functions   []Function `json:"functions"`
 	LastChange  string     `json:"lastchange"`
 	LastEvent   struct {
@@ -22,7 +30,16 @@ type Trigger struct {
 		Hostid string `json:"hostid"`
 		Name   string `json:"name"`
 	} `json:"hosts"`
-	Priority string `json:"priority"`
+	Priority     string              `json:"priority"`
+	Tags         []Tag               `json:"tags"`
+	Groups       []Group             `json:"groups"`
+	Dependencies []TriggerDependency `json:"dependencies,omitempty"`
+}
+
+// TriggerDependency is a trigger the trigger depends on.
+type TriggerDependency struct {
+	ID          string `json:"triggerid"`
+	Description string `json:"description"`
 }
 
 func (trigger *Trigger) String() string {
@@ -54,10 +71,7 @@ func (trigger *Trigger) StatusProblem() string {
 }
 
 func (trigger *Trigger) Severity() Severity {
-	value, err := strconv.Atoi(trigger.Priority)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	value, _ := strconv.Atoi(trigger.Priority)
 	return Severity(value)
 }
 
@@ -66,8 +80,12 @@ func (trigger *Trigger) DateTime() string {
 }
 
 func (trigger *Trigger) Age() string {
-	date := time.Since(trigger.date())
+	return FormatDuration(time.Since(trigger.date()))
+}
 
+// FormatDuration formats the duration using units from months to seconds,
+// e.g. 1mon 2d 3h, zero units are omitted.
+func FormatDuration(date time.Duration) string {
 	var (
 		seconds = int(date.Seconds()) % 60
 		minutes = int(date.Minutes()) % 60
@@ -79,7 +97,7 @@ func (trigger *Trigger) Age() string {
 	var units []string
 
 	units = addUnit(units, months, "mon")
-	units = addUnit(units, days%7, "d")
+	units = addUnit(units, days%30, "d")
 	units = addUnit(units, hours%24, "h")
 	units = addUnit(units, minutes, "m")
 	units = addUnit(units, seconds, "s")
@@ -88,17 +106,27 @@ func (trigger *Trigger) Age() string {
 }
 
 func (trigger *Trigger) date() time.Time {
-	date, err := strconv.ParseInt(trigger.LastChange, 10, 64)
-	if err != nil {
-		debugf("Error: %+v", err)
-	}
+	date, _ := strconv.ParseInt(trigger.LastChange, 10, 64)
 	return time.Unix(date, 0)
 }
 
 func addUnit(units []string, value int, unit string) []string {
-	if value > 1 {
+	if value > 0 {
 		units = append(units, strconv.Itoa(value)+unit)
 	}
 
 	return units
 }
+
+func (trigger *Trigger) TagsString() string {
+	return tagsString(trigger.Tags)
+}
+
+func (trigger *Trigger) GroupsString() string {
+	names := []string{}
+	for _, group := range trigger.Groups {
+		names = append(names, group.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
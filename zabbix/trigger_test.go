@@ -0,0 +1,46 @@
+package zabbix
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDuration(t *testing.T) {
+	test := assert.New(t)
+
+	testcases := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{time.Second, "1s"},
+		{time.Hour + time.Second, "1h 1s"},
+		{24 * time.Hour, "1d"},
+		{8*24*time.Hour + time.Minute, "8d 1m"},
+		{45*24*time.Hour + time.Hour, "1mon 15d 1h"},
+		{60 * 24 * time.Hour, "2mon"},
+		{0, ""},
+	}
+
+	for _, testcase := range testcases {
+		test.Equal(
+			testcase.expected, FormatDuration(testcase.duration),
+			"%s", testcase.duration,
+		)
+	}
+}
+
+func TestTriggerAge(t *testing.T) {
+	test := assert.New(t)
+
+	trigger := Trigger{
+		LastChange: strconv.FormatInt(
+			time.Now().Add(-(45*24*time.Hour + time.Hour)).Unix(), 10,
+		),
+	}
+
+	// a second can pass while the test runs
+	test.Regexp(`^1mon 15d 1h( 1s)?$`, trigger.Age())
+}
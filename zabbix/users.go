@@ -1,9 +1,12 @@
-package main
+package zabbix
 
 type User struct {
 	ID    string `json:"userid"`
 	Name  string `json:"name"`
 	Alias string `json:"alias"`
+
+	// Username is returned as of v5.4, Alias before.
+	Username string `json:"username,omitempty"`
 }
 
 type UserGroup struct {
@@ -0,0 +1,1420 @@
+// Package zabbix implements a client for the Zabbix server JSON-RPC API
+// together with typed objects for the API entities used by zabbixctl.
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goversion "github.com/hashicorp/go-version"
+	"github.com/reconquest/karma-go"
+)
+
+const (
+	// 900 is default zabbix session ttl, -60 for safety
+	ZabbixSessionTTL = 900 - 60
+
+	// HistoryBatchSize is the amount of history.get calls sent in a single
+	// batch request by GetLastHistory.
+	HistoryBatchSize = 100
+
+	// HistoryBatchConcurrency is the amount of batch requests GetLastHistory
+	// sends in parallel.
+	HistoryBatchConcurrency = 4
+)
+
+var (
+	withAuthFlag    = true
+	withoutAuthFlag = false
+
+	// methods which must be called without authorization
+	unauthorizedMethods = map[string]bool{
+		"apiinfo.version": true,
+		"user.login":      true,
+	}
+)
+
+type Params map[string]interface{}
+
+type Request struct {
+	RPC    string      `json:"jsonrpc"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+	Auth   string      `json:"auth,omitempty"`
+	ID     int64       `json:"id"`
+}
+
+// Logger receives debug and trace messages of the client, *lorg.Log
+// satisfies it.
+type Logger interface {
+	Debugf(format string, values ...interface{})
+	Tracef(format string, values ...interface{})
+}
+
+// Options describe how the client connects and authorizes to the server.
+type Options struct {
+	Address  string
+	Username string
+	Password string
+	Insecure bool
+
+	// Token is an API token (Zabbix 5.4+), when it's set it's used instead
+	// of Username and Password and the session isn't cached.
+	Token string
+
+	// SessionFile is used for caching the session between runs, caching is
+	// disabled when it's empty.
+	SessionFile string
+
+	// Logger is optional, nothing is logged when it's nil.
+	Logger Logger
+}
+
+type Zabbix struct {
+	basicURL string
+	apiURL   string
+
+	// session is renewed when the server rejects it, while requests can be
+	// sent in parallel
+	session      string
+	sessionMutex sync.RWMutex
+
+	// loginMutex serializes re-authorization of parallel requests
+	loginMutex sync.Mutex
+
+	client      *http.Client
+	requestID   int64
+	apiVersion  string
+	logger      Logger
+	username    string
+	password    string
+	sessionFile string
+	token       bool
+}
+
+func NewZabbix(ctx context.Context, options Options) (*Zabbix, error) {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: options.Insecure},
+	}
+	zabbix := &Zabbix{
+		client:      &http.Client{Transport: tr},
+		logger:      options.Logger,
+		username:    options.Username,
+		password:    options.Password,
+		sessionFile: options.SessionFile,
+	}
+
+	zabbix.debugf("* tls insecure = %v", options.Insecure)
+
+	address := options.Address
+	if !strings.Contains(address, "://") {
+		address = "https://" + address
+	}
+
+	zabbix.basicURL = strings.TrimSuffix(address, "/")
+	zabbix.apiURL = zabbix.basicURL + "/api_jsonrpc.php"
+
+	// retrieve Zabbix version
+	err := zabbix.GetAPIVersion(ctx)
+	if err != nil {
+		return nil, karma.Format(err, "can't get zabbix api version")
+	}
+
+	if options.Token != "" {
+		zabbix.debugf("* using API token instead of authorization")
+
+		zabbix.session = options.Token
+		zabbix.token = true
+
+		return zabbix, nil
+	}
+
+	sessionFile := options.SessionFile
+	if sessionFile != "" {
+		zabbix.debugf("* reading session file")
+
+		err = zabbix.restoreSession(sessionFile)
+		if err != nil {
+			return nil, karma.Format(err, "can't restore zabbix session using file '%s'", sessionFile)
+		}
+	} else {
+		zabbix.debugf("* session feature is not used")
+	}
+
+	if zabbix.session == "" {
+		err = zabbix.Login(ctx, options.Username, options.Password)
+		if err != nil {
+			return nil, karma.Format(
+				err,
+				"can't authorize user '%s' on server %s",
+				options.Username, zabbix.basicURL)
+		}
+	} else {
+		zabbix.debugf("* using session instead of authorization")
+	}
+
+	if sessionFile != "" {
+		zabbix.debugf("* rewriting session file")
+
+		// always rewrite session file, it will change modify date
+		err = zabbix.saveSession(sessionFile)
+		if err != nil {
+			return nil, karma.Format(err, "can't save zabbix session to file '%s'", sessionFile)
+		}
+	}
+
+	return zabbix, nil
+}
+
+func (zabbix *Zabbix) restoreSession(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return karma.Format(err, "can't open session file")
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return karma.Format(err, "can't stat session file")
+	}
+
+	if time.Since(stat.ModTime()).Seconds() < ZabbixSessionTTL {
+		session, err := io.ReadAll(file)
+		if err != nil {
+			return karma.Format(err, "can't read session file")
+		}
+
+		zabbix.session = string(session)
+	} else {
+		zabbix.debugf("* session is outdated")
+	}
+
+	return nil
+}
+
+func (zabbix *Zabbix) saveSession(path string) error {
+	err := os.WriteFile(path, []byte(zabbix.getSession()), 0600)
+	if err != nil {
+		return karma.Format(err, "can't write session file")
+	}
+
+	return nil
+}
+
+func (zabbix *Zabbix) GetAPIVersion(ctx context.Context) error {
+	var response ResponseAPIVersion
+
+	err := zabbix.call(
+		ctx,
+		"apiinfo.version",
+		Params{},
+		&response,
+		withoutAuthFlag)
+	if err != nil {
+		return err
+	}
+
+	zabbix.apiVersion = response.Version
+
+	return nil
+}
+
+// APIVersion returns the Zabbix API version reported by the server.
+func (zabbix *Zabbix) APIVersion() string {
+	return zabbix.apiVersion
+}
+
+// zbxVersionConstraint verifies a given version constraint against
+// the Zabbix API version.
+// It returns true when it's a match and false if not.
+func (zabbix *Zabbix) zbxVersionConstraint(v string) (bool, error) {
+	zabbixVersion, err := goversion.NewVersion(zabbix.apiVersion)
+	if err != nil {
+		return false, karma.Format(err, "error parsing apiVersion '%s'", zabbix.apiVersion)
+	}
+	constraints, err := goversion.NewConstraint(v)
+	if err != nil {
+		return false, karma.Format(err, "error setting version contraint '%s'", v)
+	}
+
+	if !constraints.Check(zabbixVersion) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (zabbix *Zabbix) Login(ctx context.Context, username, password string) error {
+	var response ResponseLogin
+
+	params := Params{
+		"password": password}
+
+	zabbix.debugf("* authorizing")
+
+	// temporary fix: v5.4 changed user.login argument 'user' to 'username'
+	// we'll implement a better API versioning methodology at a later stage.
+	zabbixVersion, err := zabbix.zbxVersionConstraint(">= 5.4")
+	if err != nil {
+		return err
+	}
+
+	if zabbixVersion {
+		zabbix.debugf("* Login: zabbix version %s >= 5.4", zabbix.apiVersion)
+		params["username"] = username
+	} else {
+		zabbix.debugf("* Login: zabbix version %s < 5.4", zabbix.apiVersion)
+		params["user"] = username
+	}
+
+	err = zabbix.call(
+		ctx,
+		"user.login",
+		params,
+		&response,
+		withoutAuthFlag,
+	)
+	if err != nil {
+		return err
+	}
+
+	zabbix.setSession(response.Token)
+
+	return nil
+}
+
+// Acknowledge updates events with the given identifiers as described by the
+// options, see AcknowledgeOptions.
+func (zabbix *Zabbix) Acknowledge(
+	ctx context.Context,
+	identifiers []string,
+	options AcknowledgeOptions,
+) error {
+	var response ResponseRaw
+
+	zabbix.debugf("* acknowledging triggers")
+
+	params, err := zabbix.acknowledgeParams(identifiers, options)
+	if err != nil {
+		return err
+	}
+
+	err = zabbix.call(
+		ctx,
+		"event.acknowledge",
+		params,
+		&response,
+		withAuthFlag,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// acknowledgeParams builds event.acknowledge params, the action bitmask and
+// supported actions depend on the server version.
+func (zabbix *Zabbix) acknowledgeParams(
+	identifiers []string,
+	options AcknowledgeOptions,
+) (Params, error) {
+	if options.Acknowledge && options.Unacknowledge {
+		return nil, errors.New(
+			"events can't be acknowledged and unacknowledged at once",
+		)
+	}
+
+	bitmask, err := zabbix.zbxVersionConstraint(">= 4.0")
+	if err != nil {
+		return nil, err
+	}
+
+	// pre v4.0 events can only be acknowledged with a message
+	if !bitmask {
+		if options.Close || options.Unacknowledge ||
+			options.Severity != nil || options.Suppress {
+			return nil, fmt.Errorf(
+				"zabbix %s can only acknowledge events, 4.0+ is required",
+				zabbix.apiVersion,
+			)
+		}
+
+		message := options.Message
+		if message == "" {
+			message = "ack"
+		}
+
+		return Params{
+			"eventids": identifiers,
+			"message":  message,
+		}, nil
+	}
+
+	params := Params{
+		"eventids": identifiers,
+	}
+
+	action := 0
+
+	if options.Close {
+		action |= acknowledgeActionClose
+	}
+
+	if options.Acknowledge {
+		action |= acknowledgeActionAcknowledge
+	}
+
+	if options.Message != "" {
+		action |= acknowledgeActionMessage
+		params["message"] = options.Message
+	}
+
+	if options.Severity != nil {
+		action |= acknowledgeActionSeverity
+		params["severity"] = int(*options.Severity)
+	}
+
+	if options.Unacknowledge {
+		supported, err := zabbix.zbxVersionConstraint(">= 5.0")
+		if err != nil {
+			return nil, err
+		}
+
+		if !supported {
+			return nil, fmt.Errorf(
+				"zabbix %s can't unacknowledge events, 5.0+ is required",
+				zabbix.apiVersion,
+			)
+		}
+
+		action |= acknowledgeActionUnacknowledge
+	}
+
+	if options.Suppress {
+		supported, err := zabbix.zbxVersionConstraint(">= 6.4")
+		if err != nil {
+			return nil, err
+		}
+
+		if !supported {
+			return nil, fmt.Errorf(
+				"zabbix %s can't suppress events, 6.4+ is required",
+				zabbix.apiVersion,
+			)
+		}
+
+		action |= acknowledgeActionSuppress
+		params["suppress_until"] = options.SuppressUntil
+	}
+
+	if action == 0 {
+		return nil, errors.New("no action specified for events")
+	}
+
+	params["action"] = action
+
+	return params, nil
+}
+
+// GetProblems returns problems along with their updates and tags, hosts of
+// problems are taken from their triggers.
+func (zabbix *Zabbix) GetProblems(ctx context.Context, extend Params) ([]Problem, error) {
+	zabbix.debugf("* retrieving problems list")
+
+	params := Params{
+		"output":             "extend",
+		"selectAcknowledges": "extend",
+		"selectTags":         "extend",
+		"sortfield":          []string{"eventid"},
+		"sortorder":          "DESC",
+	}
+
+	for key, value := range extend {
+		params[key] = value
+	}
+
+	var response ResponseProblems
+	err := zabbix.call(ctx, "problem.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := response.Data
+	if len(problems) == 0 {
+		return problems, nil
+	}
+
+	// problem.get can't select hosts
+	triggerIDs := []string{}
+	for _, problem := range problems {
+		triggerIDs = append(triggerIDs, problem.ObjectID)
+	}
+
+	var triggers ResponseTriggers
+	err = zabbix.call(ctx, "trigger.get", Params{
+		"output":       []string{"triggerid"},
+		"selectHosts":  []string{"name"},
+		"triggerids":   triggerIDs,
+		"preservekeys": true,
+	}, &triggers, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, problem := range problems {
+		problems[index].Hosts = triggers.Data[problem.ObjectID].Hosts
+	}
+
+	err = zabbix.setAcknowledgesUsers(ctx, problems)
+	if err != nil {
+		return nil, err
+	}
+
+	return problems, nil
+}
+
+// setAcknowledgesUsers sets names of users who updated problems, problem.get
+// returns only identifiers of users unlike event.get.
+func (zabbix *Zabbix) setAcknowledgesUsers(
+	ctx context.Context,
+	problems []Problem,
+) error {
+	userIDs := []string{}
+	for _, problem := range problems {
+		for _, acknowledge := range problem.Acknowledges {
+			userIDs = append(userIDs, acknowledge.UserID)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	field := "alias"
+
+	usernameField, err := zabbix.zbxVersionConstraint(">= 5.4")
+	if err != nil {
+		return err
+	}
+
+	if usernameField {
+		field = "username"
+	}
+
+	users, err := zabbix.GetUsers(ctx, Params{
+		"output":  []string{"userid", field},
+		"userids": userIDs,
+	})
+	if err != nil {
+		return err
+	}
+
+	names := map[string]User{}
+	for _, user := range users {
+		names[user.ID] = user
+	}
+
+	for _, problem := range problems {
+		for index, acknowledge := range problem.Acknowledges {
+			user := names[acknowledge.UserID]
+
+			problem.Acknowledges[index].Username = user.Username
+			problem.Acknowledges[index].Alias = user.Alias
+		}
+	}
+
+	return nil
+}
+
+// GetEvents returns PROBLEM and OK events of triggers in order of their time,
+// problem events get the time of their recovery even if the OK event isn't
+// returned. The nil value of params removes the default param.
+func (zabbix *Zabbix) GetEvents(ctx context.Context, extend Params) ([]Event, error) {
+	zabbix.debugf("* retrieving events list")
+
+	params := Params{
+		"output":              "extend",
+		"source":              0,
+		"object":              0,
+		"select_acknowledges": "extend",
+		"select_alerts":       "extend",
+		"selectHosts":         []string{"name"},
+		"selectRelatedObject": []string{"triggerid", "description", "priority"},
+		"sortfield":           []string{"clock", "eventid"},
+		"sortorder":           "ASC",
+	}
+
+	for key, value := range extend {
+		if value == nil {
+			delete(params, key)
+			continue
+		}
+
+		params[key] = value
+	}
+
+	var response ResponseEvents
+	err := zabbix.call(ctx, "event.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	events := response.Data
+
+	clocks := map[string]string{}
+	for _, event := range events {
+		clocks[event.ID] = event.Clock
+	}
+
+	missing := []string{}
+	for _, event := range events {
+		if !event.Problem() || !event.Resolved() {
+			continue
+		}
+
+		if _, ok := clocks[event.REventID]; !ok {
+			missing = append(missing, event.REventID)
+		}
+	}
+
+	// problems can be resolved after the requested period
+	if len(missing) > 0 {
+		var recoveries ResponseEvents
+		err = zabbix.call(ctx, "event.get", Params{
+			"output":   []string{"eventid", "clock"},
+			"eventids": missing,
+		}, &recoveries, withAuthFlag)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range recoveries.Data {
+			clocks[event.ID] = event.Clock
+		}
+	}
+
+	for index, event := range events {
+		if event.Problem() && event.Resolved() {
+			events[index].RClock = clocks[event.REventID]
+		}
+	}
+
+	return events, nil
+}
+
+// GetSeverityNames returns names of severities configured on the server,
+// settings.get is available as of v5.2, nil is returned before.
+func (zabbix *Zabbix) GetSeverityNames(ctx context.Context) (map[Severity]string, error) {
+	supported, err := zabbix.zbxVersionConstraint(">= 5.2")
+	if err != nil {
+		return nil, err
+	}
+
+	if !supported {
+		return nil, nil
+	}
+
+	zabbix.debugf("* retrieving names of severities")
+
+	fields := []string{}
+	for severity := SeverityNotClassified; severity <= SeverityDisaster; severity++ {
+		fields = append(fields, fmt.Sprintf("severity_name_%d", severity))
+	}
+
+	var response ResponseSettings
+	err = zabbix.call(ctx, "settings.get", Params{
+		"output": fields,
+	}, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[Severity]string{}
+	for severity := SeverityNotClassified; severity <= SeverityDisaster; severity++ {
+		name := response.Data[fields[severity]]
+		if name != "" {
+			names[severity] = name
+		}
+	}
+
+	return names, nil
+}
+
+// GetTriggers returns monitored triggers, params override defaults, the nil
+// value removes the default param, e.g. monitored for disabled triggers.
+// Severities are accepted like by problem.get and filter priorities of
+// triggers.
+func (zabbix *Zabbix) GetTriggers(ctx context.Context, extend Params) ([]Trigger, error) {
+	zabbix.debugf("* retrieving triggers list")
+
+	params := Params{
+		"monitored":         true,
+		"selectHosts":       []string{"name"},
+		"selectGroups":      []string{"groupid", "name"},
+		"selectLastEvent":   "extend",
+		"selectFunctions":   "extend",
+		"selectTags":        "extend",
+		"expandExpression":  true,
+		"expandData":        true,
+		"expandDescription": true,
+		"skipDependent":     true,
+		"preservekeys":      true,
+	}
+
+	for key, value := range extend {
+		if value == nil {
+			delete(params, key)
+			continue
+		}
+
+		params[key] = value
+	}
+
+	if severities, ok := params["severities"]; ok {
+		filter := Params{}
+		if extended, ok := params["filter"].(Params); ok {
+			for key, value := range extended {
+				filter[key] = value
+			}
+		}
+
+		filter["priority"] = severities
+		params["filter"] = filter
+
+		delete(params, "severities")
+	}
+
+	var response ResponseTriggers
+	err := zabbix.call(ctx, "trigger.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	var triggers []Trigger
+	for _, trigger := range unshuffle(response.Data) {
+		triggers = append(triggers, trigger.(Trigger))
+	}
+
+	return triggers, nil
+}
+
+// SetTriggersStatus enables or disables triggers, status is one of
+// TriggerStatusEnabled and TriggerStatusDisabled.
+func (zabbix *Zabbix) SetTriggersStatus(
+	ctx context.Context,
+	identifiers []string,
+	status string,
+) error {
+	zabbix.debugf("* updating status of triggers %s", identifiers)
+
+	params := []Params{}
+	for _, identifier := range identifiers {
+		params = append(params, Params{
+			"triggerid": identifier,
+			"status":    status,
+		})
+	}
+
+	var response ResponseRaw
+	return zabbix.call(ctx, "trigger.update", params, &response, withAuthFlag)
+}
+
+func (zabbix *Zabbix) GetMaintenances(ctx context.Context, params Params) ([]Maintenance, error) {
+	zabbix.debugf("* retrieving maintenances list")
+
+	var response ResponseMaintenances
+	err := zabbix.call(ctx, "maintenance.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	var maintenances []Maintenance
+	maintenances = append(maintenances, response.Data...)
+
+	return maintenances, nil
+}
+
+func (zabbix *Zabbix) CreateMaintenance(ctx context.Context, params Params) (Maintenances, error) {
+	zabbix.debugf("* create maintenance period")
+
+	var response ResponseMaintenancesArray
+
+	err := zabbix.call(ctx, "maintenance.create", params, &response, withAuthFlag)
+
+	return response.Data, err
+}
+
+func (zabbix *Zabbix) UpdateMaintenance(ctx context.Context, params Params) (Maintenances, error) {
+	zabbix.debugf("* update maintenance period")
+
+	var response ResponseMaintenancesArray
+
+	err := zabbix.call(ctx, "maintenance.update", params, &response, withAuthFlag)
+
+	return response.Data, err
+}
+
+func (zabbix *Zabbix) RemoveMaintenance(ctx context.Context, params interface{}) (Maintenances, error) {
+	zabbix.debugf("* remove maintenance period")
+
+	var response ResponseMaintenancesArray
+
+	err := zabbix.call(ctx, "maintenance.delete", params, &response, withAuthFlag)
+
+	return response.Data, err
+}
+
+func (zabbix *Zabbix) GetItems(ctx context.Context, params Params) ([]Item, error) {
+	zabbix.debugf("* retrieving items list")
+
+	var response ResponseItems
+	err := zabbix.call(ctx, "item.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range response.Data {
+		_, err := item.getLastClock()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response.Data, nil
+}
+
+func (zabbix *Zabbix) GetHTTPTests(ctx context.Context, params Params) ([]HTTPTest, error) {
+	zabbix.debugf("* retrieving web scenarios list")
+
+	var response ResponseHTTPTests
+	err := zabbix.call(ctx, "httptest.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+func (zabbix *Zabbix) GetUsersGroups(ctx context.Context, params Params) ([]UserGroup, error) {
+	zabbix.debugf("* retrieving usergroup list")
+
+	var response ResponseUserGroup
+	err := zabbix.call(ctx, "usergroup.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+func (zabbix *Zabbix) AddUserToGroups(ctx context.Context, groups []UserGroup, user User) error {
+	for _, group := range groups {
+		identifiers := []string{user.ID}
+
+		for _, groupUser := range group.Users {
+			identifiers = append(identifiers, groupUser.ID)
+		}
+
+		zabbix.debugf("* adding user %s to group %s", user.Alias, group.Name)
+
+		err := zabbix.call(
+			ctx,
+			"usergroup.update",
+			Params{"usrgrpid": group.ID, "userids": identifiers},
+			&ResponseRaw{},
+			withAuthFlag,
+		)
+		if err != nil {
+			return karma.Format(err, "can't update usergroup %s", group.Name)
+		}
+	}
+
+	return nil
+}
+
+func (zabbix *Zabbix) RemoveUserFromGroups(ctx context.Context, groups []UserGroup, user User) error {
+	for _, group := range groups {
+		identifiers := []string{}
+
+		for _, groupUser := range group.Users {
+			if groupUser.ID == user.ID {
+				continue
+			}
+
+			identifiers = append(identifiers, groupUser.ID)
+		}
+
+		zabbix.debugf("* removing user %s from group %s", user.Alias, group.Name)
+
+		err := zabbix.call(
+			ctx,
+			"usergroup.update",
+			Params{"usrgrpid": group.ID, "userids": identifiers},
+			&ResponseRaw{},
+			withAuthFlag,
+		)
+		if err != nil {
+			return karma.Format(err, "can't update usergroup %s", group.Name)
+		}
+	}
+
+	return nil
+}
+
+func (zabbix *Zabbix) GetUsers(ctx context.Context, params Params) ([]User, error) {
+	zabbix.debugf("* retrieving user list")
+
+	var response ResponseUsers
+	err := zabbix.call(ctx, "user.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+func (zabbix *Zabbix) GetHosts(ctx context.Context, params Params) ([]Host, error) {
+	zabbix.debugf("* retrieving host list")
+
+	var response ResponseHosts
+	err := zabbix.call(ctx, "host.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+func (zabbix *Zabbix) RemoveHosts(ctx context.Context, params interface{}) (Hosts, error) {
+	zabbix.debugf("* remove host list")
+
+	var response ResponseHostsArray
+	err := zabbix.call(ctx, "host.delete", params, &response, withAuthFlag)
+
+	return response.Data, err
+}
+
+func (zabbix *Zabbix) GetGroups(ctx context.Context, params Params) ([]Group, error) {
+	zabbix.debugf("* retrieving hostgroup list")
+
+	var response ResponseGroups
+	err := zabbix.call(ctx, "hostgroup.get", params, &response, withAuthFlag)
+
+	return response.Data, err
+}
+
+func (zabbix *Zabbix) GetTemplates(ctx context.Context, params Params) ([]Template, error) {
+	zabbix.debugf("* retrieving template list")
+
+	var response ResponseTemplates
+	err := zabbix.call(ctx, "template.get", params, &response, withAuthFlag)
+
+	return response.Data, err
+}
+
+// Call performs arbitrary API method and returns its undecoded result. An
+// error returned by the server is reported as *APIError.
+func (zabbix *Zabbix) Call(
+	ctx context.Context,
+	method string,
+	params interface{},
+) (json.RawMessage, error) {
+	var response ResponseJSON
+
+	err := zabbix.call(
+		ctx,
+		method,
+		params,
+		&response,
+		!unauthorizedMethods[method],
+	)
+	if response.Err.Message != "" {
+		return nil, &APIError{
+			Method:  method,
+			Code:    response.Err.Code,
+			Message: response.Err.Message,
+			Data:    response.Err.Data,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+func (zabbix *Zabbix) GetGraphURL(identifier string) string {
+	return zabbix.getGraphURL([]string{identifier}, "showgraph", "0")
+}
+
+func (zabbix *Zabbix) GetNormalGraphURL(identifiers []string) string {
+	return zabbix.getGraphURL(identifiers, "batchgraph", "0")
+}
+
+func (zabbix *Zabbix) GetStackedGraphURL(identifiers []string) string {
+	return zabbix.getGraphURL(identifiers, "batchgraph", "1")
+}
+
+func (zabbix *Zabbix) getGraphURL(identifiers []string, action string, graphType string) string {
+	encodedIdentifiers := []string{}
+
+	for _, identifier := range identifiers {
+		encodedIdentifiers = append(
+			encodedIdentifiers,
+			"itemids%5B%5D="+identifier,
+		)
+	}
+
+	return zabbix.basicURL + fmt.Sprintf(
+		"/history.php?action=%s&graphtype=%s&%s",
+		action,
+		graphType,
+		strings.Join(encodedIdentifiers, "&"),
+	)
+}
+
+func (zabbix *Zabbix) GetHistory(ctx context.Context, extend Params) ([]History, error) {
+	zabbix.debugf("* retrieving items history")
+
+	params := Params{
+		"output":    "extend",
+		"sortfield": "clock",
+		"sortorder": "DESC",
+	}
+
+	for key, value := range extend {
+		params[key] = value
+	}
+
+	var response ResponseHistory
+	err := zabbix.call(ctx, "history.get", params, &response, withAuthFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Data, nil
+}
+
+// GetLastHistory returns the last history value of every item by item id,
+// items without history are omitted. history.get is called for every item,
+// calls are sent in batches of HistoryBatchSize, at most
+// HistoryBatchConcurrency batches at a time.
+func (zabbix *Zabbix) GetLastHistory(
+	ctx context.Context,
+	items []Item,
+) (map[string]History, error) {
+	zabbix.debugf("* retrieving last history of %d items", len(items))
+
+	var (
+		responses = make([]ResponseHistory, len(items))
+		calls     = make([]batchCall, len(items))
+	)
+
+	for index, item := range items {
+		calls[index] = batchCall{
+			method: "history.get",
+			params: Params{
+				"output":    "extend",
+				"sortfield": "clock",
+				"sortorder": "DESC",
+				"history":   item.ValueType,
+				"itemids":   item.ID,
+				"limit":     1,
+			},
+			response: &responses[index],
+		}
+	}
+
+	err := zabbix.batches(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[string]History{}
+	for index, item := range items {
+		if len(responses[index].Data) > 0 {
+			history[item.ID] = responses[index].Data[0]
+		}
+	}
+
+	return history, nil
+}
+
+// GetLastEvents returns the last event of every trigger before the given
+// time inclusively by trigger id, triggers without events are omitted.
+// event.get is called for every trigger, calls are sent like by
+// GetLastHistory.
+func (zabbix *Zabbix) GetLastEvents(
+	ctx context.Context,
+	identifiers []string,
+	till int64,
+) (map[string]Event, error) {
+	zabbix.debugf("* retrieving last events of %d triggers", len(identifiers))
+
+	var (
+		responses = make([]ResponseEvents, len(identifiers))
+		calls     = make([]batchCall, len(identifiers))
+	)
+
+	for index, identifier := range identifiers {
+		calls[index] = batchCall{
+			method: "event.get",
+			params: Params{
+				"output": []string{
+					"eventid", "objectid", "clock", "value", "name", "severity",
+				},
+				"source":      0,
+				"object":      0,
+				"objectids":   identifier,
+				"selectHosts": []string{"name"},
+				"time_till":   till,
+				"sortfield":   []string{"clock", "eventid"},
+				"sortorder":   "DESC",
+				"limit":       1,
+			},
+			response: &responses[index],
+		}
+	}
+
+	err := zabbix.batches(ctx, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	events := map[string]Event{}
+	for index, identifier := range identifiers {
+		if len(responses[index].Data) > 0 {
+			events[identifier] = responses[index].Data[0]
+		}
+	}
+
+	return events, nil
+}
+
+// batches performs calls in batches of HistoryBatchSize, at most
+// HistoryBatchConcurrency batches at a time.
+func (zabbix *Zabbix) batches(ctx context.Context, calls []batchCall) error {
+	var (
+		errs      = make(chan error, (len(calls)+HistoryBatchSize-1)/HistoryBatchSize)
+		semaphore = make(chan struct{}, HistoryBatchConcurrency)
+		group     sync.WaitGroup
+	)
+
+	for start := 0; start < len(calls); start += HistoryBatchSize {
+		end := start + HistoryBatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		group.Add(1)
+
+		go func(calls []batchCall) {
+			defer group.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			errs <- zabbix.batch(ctx, calls)
+		}(calls[start:end])
+	}
+
+	group.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// call performs the API request, if the server rejects the session the user
+// is authorized again and the request is retried once.
+func (zabbix *Zabbix) call(
+	ctx context.Context,
+	method string,
+	params interface{},
+	response Response,
+	authFlag bool,
+) error {
+	session := zabbix.getSession()
+
+	err := zabbix.request(ctx, method, params, response, authFlag)
+	if err == nil || !authFlag || method == "user.login" || zabbix.token ||
+		zabbix.username == "" || !response.SessionExpired() {
+		return err
+	}
+
+	zabbix.debugf("* session was rejected by the server, re-authorizing")
+
+	err = zabbix.relogin(ctx, session)
+	if err != nil {
+		return karma.Format(
+			err,
+			"can't re-authorize user '%s' on server %s",
+			zabbix.username, zabbix.basicURL,
+		)
+	}
+
+	// response still holds the error of the first attempt
+	reflect.ValueOf(response).Elem().Set(
+		reflect.Zero(reflect.TypeOf(response).Elem()),
+	)
+
+	return zabbix.request(ctx, method, params, response, authFlag)
+}
+
+// relogin authorizes the user again unless the rejected session was already
+// renewed by another request.
+func (zabbix *Zabbix) relogin(ctx context.Context, rejected string) error {
+	zabbix.loginMutex.Lock()
+	defer zabbix.loginMutex.Unlock()
+
+	if zabbix.getSession() != rejected {
+		zabbix.debugf("* session was renewed by another request")
+		return nil
+	}
+
+	err := zabbix.Login(ctx, zabbix.username, zabbix.password)
+	if err != nil {
+		return err
+	}
+
+	if zabbix.sessionFile != "" {
+		zabbix.debugf("* rewriting session file")
+
+		err = zabbix.saveSession(zabbix.sessionFile)
+		if err != nil {
+			return karma.Format(
+				err,
+				"can't save zabbix session to file '%s'",
+				zabbix.sessionFile,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (zabbix *Zabbix) getSession() string {
+	zabbix.sessionMutex.RLock()
+	defer zabbix.sessionMutex.RUnlock()
+
+	return zabbix.session
+}
+
+func (zabbix *Zabbix) setSession(session string) {
+	zabbix.sessionMutex.Lock()
+	defer zabbix.sessionMutex.Unlock()
+
+	zabbix.session = session
+}
+
+// useBearerToken reports whether the session is sent in the Authorization
+// header, as of v6.4, instead of the auth parameter.
+func (zabbix *Zabbix) useBearerToken(authFlag bool) (bool, error) {
+	if !authFlag {
+		return false, nil
+	}
+
+	return zabbix.zbxVersionConstraint(">= 6.4")
+}
+
+// newRequest creates the request of the method with the next identifier.
+func (zabbix *Zabbix) newRequest(
+	method string,
+	params interface{},
+	authFlag bool,
+	useBearerToken bool,
+) Request {
+	zabbix.debugf("~> %s", method)
+	zabbix.debugParams(params)
+
+	request := Request{
+		RPC:    "2.0",
+		Method: method,
+		Params: params,
+		ID:     atomic.AddInt64(&zabbix.requestID, 1),
+	}
+
+	// pre v6.4 we use auth parameter
+	if authFlag && !useBearerToken {
+		request.Auth = zabbix.getSession()
+	}
+
+	return request
+}
+
+func (zabbix *Zabbix) request(
+	ctx context.Context,
+	method string,
+	params interface{},
+	response Response,
+	authFlag bool,
+) error {
+	useBearerToken, err := zabbix.useBearerToken(authFlag)
+	if err != nil {
+		return err
+	}
+
+	request := zabbix.newRequest(method, params, authFlag, useBearerToken)
+
+	body, err := zabbix.post(ctx, request, useBearerToken)
+	if err != nil {
+		return err
+	}
+
+	err = decodeResponse(body, response)
+	if err != nil {
+		return err
+	}
+
+	err = response.Error()
+	if err != nil {
+		return karma.Format(err, "zabbix returned error while working with api method %s", method)
+	}
+
+	return nil
+}
+
+// post sends the request (or the batch of requests) to the API and returns
+// the response body.
+func (zabbix *Zabbix) post(
+	ctx context.Context,
+	request interface{},
+	useBearerToken bool,
+) ([]byte, error) {
+	buffer, err := json.Marshal(request)
+	if err != nil {
+		return nil, karma.Format(err, "can't encode request to JSON")
+	}
+
+	payload, err := http.NewRequestWithContext(
+		ctx, "POST", zabbix.apiURL, bytes.NewReader(buffer),
+	)
+	if err != nil {
+		return nil, karma.Format(err, "can't create http request")
+	}
+
+	payload.ContentLength = int64(len(buffer))
+	payload.Header.Add("Content-Type", "application/json-rpc")
+	payload.Header.Add("User-Agent", "zabbixctl")
+	if useBearerToken {
+		payload.Header.Add("Authorization", "Bearer "+zabbix.getSession())
+	}
+
+	resource, err := zabbix.client.Do(payload)
+	if err != nil {
+		return nil, karma.Format(err, "http request to zabbix api failed")
+	}
+	defer resource.Body.Close()
+
+	body, err := io.ReadAll(resource.Body)
+	if err != nil {
+		return nil, karma.Format(err, "can't read zabbix api response body")
+	}
+
+	zabbix.debugf("<~ %s", resource.Status)
+
+	if zabbix.logger != nil {
+		var tracing bytes.Buffer
+		err = json.Indent(&tracing, body, "", "  ")
+		if err != nil {
+			zabbix.logger.Tracef("<~ %s", body)
+		} else {
+			zabbix.logger.Tracef("<~ %s", tracing.String())
+		}
+	}
+
+	return body, nil
+}
+
+func decodeResponse(body []byte, response Response) error {
+	err := json.Unmarshal(body, response)
+	if err != nil {
+		// There is can be bullshit case when zabbix sends empty `result`
+		// array and json.Unmarshal triggers the error with message about
+		// failed type conversion to map[].
+		//
+		// So, we must check that err is not this case.
+		var raw ResponseRaw
+		rawErr := json.Unmarshal(body, &raw)
+		if rawErr != nil {
+			// return original error
+			return err
+		}
+
+		if result, ok := raw.Result.([]interface{}); ok && len(result) == 0 {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (zabbix *Zabbix) debugf(format string, values ...interface{}) {
+	if zabbix.logger != nil {
+		zabbix.logger.Debugf(format, values...)
+	}
+}
+
+func (zabbix *Zabbix) debugParams(params interface{}, prefix ...string) {
+	if zabbix.logger == nil {
+		return
+	}
+
+	switch params.(type) {
+	case Params:
+		p, _ := params.(Params)
+		for key, value := range p {
+			if valueParams, ok := value.(Params); ok {
+				zabbix.debugParams(valueParams, append(prefix, key)...)
+				continue
+			}
+
+			if key == "password" {
+				value = "**********"
+			}
+
+			zabbix.debugf(
+				"** %s%s: %v",
+				strings.Join(append(prefix, ""), "."),
+				key, value,
+			)
+		}
+	case interface{}:
+		if p, ok := params.([]string); ok {
+			for _, value := range p {
+				zabbix.debugf("** %v", value)
+			}
+		}
+	}
+}
+
+func unshuffle(target interface{}) []interface{} {
+	tears := reflect.ValueOf(target)
+
+	var values []interface{}
+	for _, key := range tears.MapKeys() {
+		values = append(values, tears.MapIndex(key).Interface())
+	}
+
+	return values
+}
@@ -1,6 +1,7 @@
-package main
+package zabbix
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -173,13 +174,39 @@ func TestIssue18(t *testing.T) {
 	zabbix.apiURL = testserver.URL
 	zabbix.apiVersion = "5.0.0"
 
-	items, err := zabbix.GetItems(Params{"hostids": []string{"10084"}})
+	items, err := zabbix.GetItems(context.Background(), Params{"hostids": []string{"10084"}})
 	test.NoError(err)
 	test.Len(items, 2)
 
-	test.Equal("1548924066", items[0].getLastClock())
-	test.Equal("0", items[1].getLastClock())
+	clock, err := items[0].getLastClock()
+	test.NoError(err)
+	test.Equal("1548924066", clock)
+
+	clock, err = items[1].getLastClock()
+	test.NoError(err)
+	test.Equal("0", clock)
 
 	test.Equal(time.Unix(1548924066, 0).Format(TimeFormat), items[0].DateTime())
 	test.Equal("-", items[1].DateTime())
 }
+
+func TestGetItems_UnexpectedLastClock(t *testing.T) {
+	test := assert.New(t)
+
+	testserver := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"jsonrpc": "2.0", "id": 1, "result": [
+				{"itemid": "1", "name": "CPU load", "lastclock": true}
+			]}`)
+		},
+	))
+	defer testserver.Close()
+
+	zabbix := &Zabbix{}
+	zabbix.client = testserver.Client()
+	zabbix.apiURL = testserver.URL
+	zabbix.apiVersion = "5.0.0"
+
+	_, err := zabbix.GetItems(context.Background(), Params{})
+	test.ErrorContains(err, "unexpected type 'bool' for lastclock in item ID 1")
+}